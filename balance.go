@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+func cmdBalance() error {
+	matrixConfig, err := getMatrixConfigWithPassword()
+	if err != nil {
+		return err
+	}
+
+	client, err := NewMatrixClient(matrixConfig)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	matrixLog.Debug("get balance")
+	balance, err := client.GetBalance()
+	if err != nil {
+		return fmt.Errorf("could not retrieve balance: %s", err.Error())
+	}
+
+	if cli.Balance.JSON {
+		data, err := json.MarshalIndent(balance, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal balance to json")
+		}
+		stdio.Println("%s", string(data))
+		return nil
+	}
+
+	for _, account := range balance.Accounts {
+		stdio.Println("%-22s %s", account.Title+":", formatAccount(account))
+	}
+	return nil
+}
+
+func formatAccount(account MatrixAccount) string {
+	switch account.Name {
+	case AccountFlexiTime, AccountOvertime:
+		return formatFlexiTime(account.Duration)
+	case AccountMonthlyTarget, AccountMonthlyActual:
+		return formatDurationMinutes(account.Duration)
+	default:
+		return account.String()
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"time"
+)
+
+// BreakRecommendation is the optimal break to take for reaching the target time with minimal presence time.
+type BreakRecommendation struct {
+	// Required is the break Matrix deducts for the target time and Taken the break taken so far.
+	Required time.Duration
+	Taken    time.Duration
+	// Length is the break that does not extend the presence time because Matrix deducts it anyway. It is zero if the minimum break is already satisfied.
+	Length time.Duration
+	// LatestStart is the latest time to start the break without working too long continuously or taking the break directly before leaving.
+	LatestStart time.Time
+}
+
+// Wasted returns true if any further break extends the presence time.
+func (r *BreakRecommendation) Wasted() bool {
+	return r.Length <= 0
+}
+
+// requiredBreakTime returns the minimum break Matrix deducts for an accounted work time.
+func requiredBreakTime(workTime time.Duration) time.Duration {
+	switch {
+	case workTime > 9*time.Hour:
+		return 45 * time.Minute
+	case workTime > 6*time.Hour:
+		return 30 * time.Minute
+	default:
+		return 0
+	}
+}
+
+// RecommendBreak returns the break to take for reaching the target time. False is returned if the clock is not ticking.
+func RecommendBreak(status *Status, now time.Time) (*BreakRecommendation, bool) {
+	if !status.Ticking() {
+		return nil, false
+	}
+
+	r := &BreakRecommendation{Required: requiredBreakTime(status.TargetTime), Taken: status.BreakTime}
+	r.Length = r.Required - r.Taken
+	if r.Length <= 0 {
+		r.Length = 0
+		return r, true
+	}
+
+	// the current work segment starts after the last interruption that counts as break
+	segmentStart := status.StartTime
+	for i := len(status.Entries) - 1; i > 0; i-- {
+		e := status.Entries[i]
+		if e.Type == EntryTypeCome && status.Entries[i-1].Type == EntryTypeLeave && e.Time.Sub(status.Entries[i-1].Time) >= complianceMinBreak {
+			segmentStart = e.Time
+			break
+		}
+	}
+	r.LatestStart = segmentStart.Add(6 * time.Hour)
+	if leave := status.TargetLeaveTime().Time; !leave.IsZero() {
+		if latest := leave.Add(-complianceBreakMargin - r.Length); latest.Before(r.LatestStart) {
+			r.LatestStart = latest
+		}
+	}
+	if r.LatestStart.Before(now) {
+		r.LatestStart = now
+	}
+	return r, true
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendBreak(t *testing.T) {
+	day := time.Date(2026, time.October, 14, 0, 0, 0, 0, time.Local)
+	recommend := func(target time.Duration, now time.Duration, entries ...Entry) (*BreakRecommendation, bool) {
+		status, err := ComputeStatus(entries, 0, target, nil)
+		require.NoError(t, err)
+		return RecommendBreak(status, day.Add(now))
+	}
+
+	// no break yet: latest before 6h of continuous work
+	r, ok := recommend(8*time.Hour, dur(10, 0), Entry{Type: EntryTypeCome, Time: day.Add(dur(8, 0))})
+	require.True(t, ok)
+	assert.Equal(t, dur(0, 30), r.Length)
+	assert.Equal(t, day.Add(dur(14, 0)), r.LatestStart)
+	assert.False(t, r.Wasted())
+
+	// short break taken: the rest must end 30 minutes before leaving
+	r, ok = recommend(8*time.Hour, dur(13, 0),
+		Entry{Type: EntryTypeCome, Time: day.Add(dur(8, 0))},
+		Entry{Type: EntryTypeLeave, Time: day.Add(dur(12, 0))},
+		Entry{Type: EntryTypeCome, Time: day.Add(dur(12, 20))})
+	require.True(t, ok)
+	assert.Equal(t, dur(0, 20), r.Taken)
+	assert.Equal(t, dur(0, 10), r.Length)
+	assert.Equal(t, day.Add(dur(15, 50)), r.LatestStart)
+
+	// latest start already passed
+	r, ok = recommend(8*time.Hour, dur(16, 0), Entry{Type: EntryTypeCome, Time: day.Add(dur(8, 0))})
+	require.True(t, ok)
+	assert.Equal(t, day.Add(dur(16, 0)), r.LatestStart)
+
+	// 45 minutes are required above 9 hours
+	r, ok = recommend(dur(9, 30), dur(13, 0),
+		Entry{Type: EntryTypeCome, Time: day.Add(dur(8, 0))},
+		Entry{Type: EntryTypeLeave, Time: day.Add(dur(12, 0))},
+		Entry{Type: EntryTypeCome, Time: day.Add(dur(12, 30))})
+	require.True(t, ok)
+	assert.Equal(t, dur(0, 15), r.Length)
+
+	// minimum already satisfied
+	r, ok = recommend(8*time.Hour, dur(13, 0),
+		Entry{Type: EntryTypeCome, Time: day.Add(dur(8, 0))},
+		Entry{Type: EntryTypeLeave, Time: day.Add(dur(12, 0))},
+		Entry{Type: EntryTypeCome, Time: day.Add(dur(12, 30))})
+	require.True(t, ok)
+	assert.True(t, r.Wasted())
+
+	r, ok = recommend(6*time.Hour, dur(10, 0), Entry{Type: EntryTypeCome, Time: day.Add(dur(8, 0))})
+	require.True(t, ok)
+	assert.True(t, r.Wasted())
+
+	_, ok = recommend(8*time.Hour, dur(17, 0),
+		Entry{Type: EntryTypeCome, Time: day.Add(dur(8, 0))},
+		Entry{Type: EntryTypeLeave, Time: day.Add(dur(17, 0))})
+	assert.False(t, ok)
+}
+
+func TestShowTemplateBreak(t *testing.T) {
+	oldColors, oldColorEnd := colors, colorEnd
+	disableColors()
+	defer func() { colors, colorEnd = oldColors, oldColorEnd }()
+
+	tmpl, err := loadShowTemplate("default")
+	require.NoError(t, err)
+
+	data := testShowData(t)
+	data.Break = &BreakRecommendation{Required: dur(0, 30), Taken: dur(0, 20), Length: dur(0, 10), LatestStart: truncateDay(data.Now).Add(dur(15, 50))}
+	var sb strings.Builder
+	require.NoError(t, renderShowTemplate(&sb, tmpl, data))
+	assert.Contains(t, sb.String(), "break:               00:30\ntake break:          00:10, start by 15:50 (deducted anyway)\n")
+
+	data.Break = &BreakRecommendation{Required: dur(0, 30), Taken: dur(0, 30)}
+	sb.Reset()
+	require.NoError(t, renderShowTemplate(&sb, tmpl, data))
+	assert.Contains(t, sb.String(), "take break:          none, a further break only extends your presence\n")
+}
@@ -9,13 +9,24 @@ import (
 	"github.com/sbreitf1/gohome/internal/pkg/stdio"
 )
 
+var (
+	// cacheLog logs reads and writes of the entries cache.
+	cacheLog = stdio.Logger("cache")
+)
+
+// fetchFailureData records when fetching entries from Matrix failed the last time.
+type fetchFailureData struct {
+	Time time.Time
+}
+
 type cacheData struct {
 	Entries   []Entry
 	FlexiTime time.Duration
 	Time      time.Time
 }
 
-func ReadCache() ([]Entry, time.Duration, time.Time, bool, error) {
+// ReadCache returns today's entries and flexi-time balance if the cache is younger than maxAge.
+func ReadCache(maxAge time.Duration) ([]Entry, time.Duration, time.Time, bool, error) {
 	configDir := getConfigDir()
 	cacheFile := filepath.Join(configDir, "cache.json")
 
@@ -34,18 +45,22 @@ func ReadCache() ([]Entry, time.Duration, time.Time, bool, error) {
 
 	now := time.Now()
 	if cd.Time.Year() != now.Year() || cd.Time.Month() != now.Month() || cd.Time.Day() != now.Day() {
-		stdio.Debug("cache is for another day")
+		cacheLog.Debug("cache is for another day")
 		return nil, 0, time.Time{}, false, nil
 	}
-	maxCacheAge := time.Duration(cli.Show.CacheTimeSeconds) * time.Second
-	if cd.Time.Before(now.Add(-maxCacheAge)) {
-		stdio.Debug("cache is older than max age of %v", maxCacheAge)
+	if cd.Time.Before(now.Add(-maxAge)) {
+		cacheLog.Debug("cache is older than max age", "maxAge", maxAge)
 		return nil, 0, time.Time{}, false, nil
 	}
 	return cd.Entries, cd.FlexiTime, cd.Time, true, nil
 }
 
+// WriteCache stores today's entries in the cache and records them in the history.
 func WriteCache(entries []Entry, flexiTime time.Duration) error {
+	if err := RecordHistory(entries, flexiTime); err != nil {
+		stdio.Warn("record history failed: %s", err.Error())
+	}
+
 	configDir := getConfigDir()
 	cacheFile := filepath.Join(configDir, "cache.json")
 
@@ -60,3 +75,48 @@ func WriteCache(entries []Entry, flexiTime time.Duration) error {
 
 	return os.WriteFile(cacheFile, data, os.ModePerm)
 }
+
+// InvalidateCache removes the cache file so the next run fetches entries from Matrix.
+func InvalidateCache() error {
+	configDir := getConfigDir()
+	cacheFile := filepath.Join(configDir, "cache.json")
+
+	if err := os.Remove(cacheFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ReadFetchFailure returns the time of the last failed fetch or the zero time if the last fetch succeeded.
+func ReadFetchFailure() (time.Time, error) {
+	data, err := os.ReadFile(filepath.Join(getConfigDir(), "fetch-failure.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	var fd fetchFailureData
+	if err := json.Unmarshal(data, &fd); err != nil {
+		return time.Time{}, err
+	}
+	return fd.Time, nil
+}
+
+// WriteFetchFailure remembers that fetching entries from Matrix failed now.
+func WriteFetchFailure() error {
+	data, err := json.MarshalIndent(fetchFailureData{Time: time.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(getConfigDir(), "fetch-failure.json"), data, os.ModePerm)
+}
+
+// ClearFetchFailure forgets a failed fetch after entries have been fetched successfully.
+func ClearFetchFailure() error {
+	if err := os.Remove(filepath.Join(getConfigDir(), "fetch-failure.json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+const (
+	// bookingConfirmTolerance is the max difference between local time and the booking time listed by Matrix.
+	bookingConfirmTolerance = 2 * time.Minute
+)
+
+var (
+	clockActions = map[string]EntryType{
+		"in":   EntryTypeCome,
+		"out":  EntryTypeLeave,
+		"trip": EntryTypeTrip,
+	}
+)
+
+func cmdClock() error {
+	entryType, ok := clockActions[cli.Clock.Action]
+	if !ok {
+		return fmt.Errorf("unknown clock action %q", cli.Clock.Action)
+	}
+
+	matrixConfig, err := getMatrixConfigWithPassword()
+	if err != nil {
+		return err
+	}
+
+	client, err := NewMatrixClient(matrixConfig)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return clock(client, entryType, cli.Clock.DryRun, cli.Clock.Force)
+}
+
+// clock books an entry of the given type and confirms it by reading the booking list afterwards.
+func clock(client *MatrixClient, entryType EntryType, dryRun, force bool) error {
+	matrixLog.Debug("get entries before booking")
+	entriesBefore, err := client.GetEntries()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve entries: %s", err.Error())
+	}
+	if err := checkBookingTransition(entriesBefore, entryType); err != nil {
+		if !force {
+			return fmt.Errorf("%s (use --force to book anyway)", err.Error())
+		}
+		stdio.Warn("%s", err.Error())
+	}
+
+	matrixLog.Debug("book", "type", entryType)
+	bookingTime := time.Now()
+	values, err := client.Book(entryType, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		form := redactMatrixForm(values.Encode())
+		keys := make([]string, 0, len(form))
+		for k := range form {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		stdio.Println("dry-run: would book %q at %s with form values:", entryType, bookingTime.Format("15:04"))
+		for _, k := range keys {
+			stdio.Println("  %s = %s", k, form[k])
+		}
+		return nil
+	}
+
+	if err := InvalidateCache(); err != nil {
+		stdio.Warn("invalidate cache failed: %s", err.Error())
+	}
+
+	matrixLog.Debug("get entries after booking")
+	entriesAfter, err := client.GetEntries()
+	if err != nil {
+		return fmt.Errorf("booking submitted, but failed to confirm: %s", err.Error())
+	}
+	entry, ok := findNewEntry(entriesBefore, entriesAfter, entryType, bookingTime)
+	if !ok {
+		return fmt.Errorf("booking submitted, but no new %q entry is listed by Matrix", entryType)
+	}
+
+	stdio.Println("booked %q at %s", entry.Type, entry.Time.Format("15:04"))
+	return nil
+}
+
+// checkBookingTransition returns an error if a booking of the given type does not follow the current state.
+func checkBookingTransition(entries []Entry, entryType EntryType) error {
+	state := EntryTypeLeave
+	if len(entries) > 0 {
+		state = entries[len(entries)-1].Type
+	}
+
+	switch entryType {
+	case EntryTypeCome:
+		if state == EntryTypeCome {
+			return fmt.Errorf("clock is already ticking")
+		}
+	case EntryTypeLeave, EntryTypeTrip:
+		if state != EntryTypeCome {
+			return fmt.Errorf("clock is not ticking at the moment")
+		}
+	}
+	return nil
+}
+
+// findNewEntry returns an entry of the given type near the booking time that has not been present before.
+func findNewEntry(before, after []Entry, entryType EntryType, bookingTime time.Time) (Entry, bool) {
+	isCandidate := func(e Entry) bool {
+		diff := e.Time.Sub(bookingTime)
+		return e.Type == entryType && diff > -bookingConfirmTolerance && diff < bookingConfirmTolerance
+	}
+
+	var countBefore int
+	for _, e := range before {
+		if isCandidate(e) {
+			countBefore++
+		}
+	}
+	var candidate Entry
+	var countAfter int
+	for _, e := range after {
+		if isCandidate(e) {
+			candidate = e
+			countAfter++
+		}
+	}
+	return candidate, countAfter > countBefore
+}
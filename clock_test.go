@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTempConfigDir redirects the config dir to a temporary directory for the duration of a test.
+func useTempConfigDir(t *testing.T) string {
+	oldConfigHome := xdg.ConfigHome
+	xdg.ConfigHome = t.TempDir()
+	t.Cleanup(func() { xdg.ConfigHome = oldConfigHome })
+
+	dir := getXDGConfigDir()
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	return dir
+}
+
+func TestClockOut(t *testing.T) {
+	dir := useTempConfigDir(t)
+	require.NoError(t, WriteCache([]Entry{{Type: EntryTypeCome, Time: time.Now()}}, 0))
+
+	fm := newFakeMatrix(t)
+	client, err := NewMatrixClient(fm.Config())
+	require.NoError(t, err)
+
+	require.NoError(t, clock(client, EntryTypeLeave, false, false))
+
+	entries, err := client.GetEntries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, EntryTypeLeave, entries[1].Type)
+
+	_, err = os.Stat(filepath.Join(dir, "cache.json"))
+	assert.True(t, os.IsNotExist(err), "cache has not been invalidated")
+}
+
+func TestClockDryRun(t *testing.T) {
+	useTempConfigDir(t)
+	fm := newFakeMatrix(t)
+	client, err := NewMatrixClient(fm.Config())
+	require.NoError(t, err)
+
+	require.NoError(t, clock(client, EntryTypeTrip, true, false))
+
+	for _, form := range fm.Forms() {
+		assert.NotContains(t, form, "mainbody:terminal:trip")
+	}
+	entries, err := client.GetEntries()
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestClockInvalidTransition(t *testing.T) {
+	useTempConfigDir(t)
+	fm := newFakeMatrix(t)
+	client, err := NewMatrixClient(fm.Config())
+	require.NoError(t, err)
+
+	assert.Error(t, clock(client, EntryTypeCome, false, false))
+	require.NoError(t, clock(client, EntryTypeCome, false, true))
+}
+
+func TestClockRejected(t *testing.T) {
+	useTempConfigDir(t)
+	fm := newFakeMatrix(t)
+	fm.BookingError = "Booking not allowed"
+	client, err := NewMatrixClient(fm.Config())
+	require.NoError(t, err)
+
+	err = clock(client, EntryTypeLeave, false, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Booking not allowed")
+}
+
+func TestFindNewEntry(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 16, 40, 30, 0, time.Local)
+	before := []Entry{{Type: EntryTypeCome, Time: now.Add(-8 * time.Hour)}}
+	after := append(before, Entry{Type: EntryTypeLeave, Time: now.Truncate(time.Minute)})
+
+	entry, ok := findNewEntry(before, after, EntryTypeLeave, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Truncate(time.Minute), entry.Time)
+
+	_, ok = findNewEntry(before, before, EntryTypeLeave, now)
+	assert.False(t, ok)
+	_, ok = findNewEntry(before, after, EntryTypeTrip, now)
+	assert.False(t, ok)
+}
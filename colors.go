@@ -3,9 +3,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/sbreitf1/gohome/internal/pkg/stdio"
@@ -25,64 +28,65 @@ type colorsDef struct {
 }
 
 var (
-	patternColor = regexp.MustCompile(`^(\d+);(\d+)$`)
+	patternColor = regexp.MustCompile(`^\d+(;\d+)*$`)
 	colors       colorsDef
 	colorEnd     = "\033[0m"
 )
 
 func initColors() {
-	if !stdio.SupportsColors() {
+	level := stdio.ColorSupport()
+	if level == stdio.ColorNone {
 		stdio.Debug("disable color support")
 		disableColors()
 	} else {
 		readColors()
+		adaptColors(&colors, level)
 	}
 }
 
 func disableColors() {
-	colors.ComeEntry = ""
-	colors.LeaveEntry = ""
-	colors.TripEntry = ""
-	colors.CacheHint = ""
-	colors.WorkTime = ""
-	colors.BreakEntry = ""
-	colors.BreakInfo = ""
-	colors.LeaveTime = ""
-	colors.FlexiTimePlus = ""
-	colors.FlexiTimeMinus = ""
+	for _, f := range colorFields(&colors) {
+		*f.Value = ""
+	}
 	colorEnd = ""
 }
 
 func setDefaultColors() {
-	colors.ComeEntry = "\033[2;32m"
-	colors.LeaveEntry = "\033[2;31m"
-	colors.TripEntry = "\033[2;33m"
-	colors.CacheHint = "\033[1;30m"
-	colors.WorkTime = "\033[1;37m"
-	colors.BreakEntry = "\033[2;37m"
-	colors.BreakInfo = "\033[1;30m"
-	colors.LeaveTime = "\033[1;34m"
-	colors.FlexiTimePlus = "\033[0;32m"
-	colors.FlexiTimeMinus = "\033[0;31m"
+	if err := applyTheme(&colors, builtinThemes["default"]); err != nil {
+		panic(err)
+	}
+}
+
+// colorField is a named color of colorsDef.
+type colorField struct {
+	Name  string
+	Value *string
+}
+
+// colorFields returns all colors of c in declaration order.
+func colorFields(c *colorsDef) []colorField {
+	v := reflect.ValueOf(c).Elem()
+	fields := make([]colorField, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		fields = append(fields, colorField{Name: v.Type().Field(i).Name, Value: v.Field(i).Addr().Interface().(*string)})
+	}
+	return fields
 }
 
 func readColors() {
 	setDefaultColors()
 	dir := getConfigDir()
-	data, err := os.ReadFile(filepath.Join(dir, "colors.json"))
-	if err != nil {
-		if !os.IsNotExist(err) {
-			stdio.Warn("failed to read colors.json: %s", err.Error())
-		}
+	file := filepath.Join(dir, "colors.json")
+	if _, err := os.Stat(file); os.IsNotExist(err) {
 		return
 	}
-	var newColors colorsDef
-	if err := json.Unmarshal(data, &newColors); err != nil {
-		stdio.Warn("failed to unmarshal colors config")
+	theme, err := readTheme(file)
+	if err != nil {
+		stdio.Warn("failed to read colors.json: %s", err.Error())
 		return
 	}
 	stdio.Debug("import colors from colors.json")
-	importColors(&colors, newColors)
+	importColors(&colors, theme)
 }
 
 func dumpColors() error {
@@ -103,39 +107,179 @@ func dumpColors() error {
 	return nil
 }
 
+// shortenColors returns the SGR parameters of all escape sequences.
 func shortenColors(colors colorsDef) colorsDef {
-	return colorsDef{
-		ComeEntry:      strings.ReplaceAll(strings.ReplaceAll(colors.ComeEntry, "\033[", ""), "m", ""),
-		LeaveEntry:     strings.ReplaceAll(strings.ReplaceAll(colors.LeaveEntry, "\033[", ""), "m", ""),
-		TripEntry:      strings.ReplaceAll(strings.ReplaceAll(colors.TripEntry, "\033[", ""), "m", ""),
-		CacheHint:      strings.ReplaceAll(strings.ReplaceAll(colors.TripEntry, "\033[", ""), "m", ""),
-		WorkTime:       strings.ReplaceAll(strings.ReplaceAll(colors.WorkTime, "\033[", ""), "m", ""),
-		BreakEntry:     strings.ReplaceAll(strings.ReplaceAll(colors.BreakEntry, "\033[", ""), "m", ""),
-		BreakInfo:      strings.ReplaceAll(strings.ReplaceAll(colors.BreakInfo, "\033[", ""), "m", ""),
-		LeaveTime:      strings.ReplaceAll(strings.ReplaceAll(colors.LeaveTime, "\033[", ""), "m", ""),
-		FlexiTimePlus:  strings.ReplaceAll(strings.ReplaceAll(colors.FlexiTimePlus, "\033[", ""), "m", ""),
-		FlexiTimeMinus: strings.ReplaceAll(strings.ReplaceAll(colors.FlexiTimeMinus, "\033[", ""), "m", ""),
+	var short colorsDef
+	shortFields := colorFields(&short)
+	for i, f := range colorFields(&colors) {
+		*shortFields[i].Value = strings.TrimSuffix(strings.TrimPrefix(*f.Value, "\033["), "m")
 	}
+	return short
 }
 
+// importColors overwrites all colors of dst that are given as style in src. Invalid styles are skipped with a warning.
 func importColors(dst *colorsDef, src colorsDef) {
-	importColor(&dst.ComeEntry, src.ComeEntry, "ComeEntry")
-	importColor(&dst.LeaveEntry, src.LeaveEntry, "LeaveEntry")
-	importColor(&dst.TripEntry, src.TripEntry, "TripEntry")
-	importColor(&dst.CacheHint, src.CacheHint, "CacheHint")
-	importColor(&dst.WorkTime, src.WorkTime, "WorkTime")
-	importColor(&dst.BreakEntry, src.BreakEntry, "BreakEntry")
-	importColor(&dst.BreakInfo, src.BreakInfo, "BreakInfo")
-	importColor(&dst.LeaveTime, src.LeaveTime, "LeaveTime")
-	importColor(&dst.FlexiTimePlus, src.FlexiTimePlus, "FlexiTimePlus")
-	importColor(&dst.FlexiTimeMinus, src.FlexiTimeMinus, "FlexiTimeMinus")
+	srcFields := colorFields(&src)
+	for i, f := range colorFields(dst) {
+		if len(*srcFields[i].Value) > 0 {
+			importColor(f.Value, *srcFields[i].Value, f.Name)
+		}
+	}
 }
 
 func importColor(dst *string, src string, fieldName string) {
-	m := patternColor.FindStringSubmatch(src)
-	if len(m) != 3 {
-		stdio.Warn("color for %q invalid", fieldName)
+	params, err := parseStyle(src)
+	if err != nil {
+		stdio.Warn("color for %q invalid: %s", fieldName, err.Error())
 		return
 	}
-	*dst = fmt.Sprintf("\033[%s;%sm", m[1], m[2])
+	*dst = fmt.Sprintf("\033[%sm", params)
+}
+
+// adaptColors converts 256 and true colors to the closest colors the terminal supports.
+func adaptColors(c *colorsDef, level stdio.ColorLevel) {
+	for _, f := range colorFields(c) {
+		*f.Value = adaptColor(*f.Value, level)
+	}
+}
+
+// adaptColor rewrites the extended color parameters 38;5;n, 38;2;r;g;b and their background variants of an escape sequence.
+func adaptColor(seq string, level stdio.ColorLevel) string {
+	if level >= stdio.ColorTrue || !strings.HasPrefix(seq, "\033[") {
+		return seq
+	}
+	params := strings.Split(strings.TrimSuffix(strings.TrimPrefix(seq, "\033["), "m"), ";")
+	out := make([]string, 0, len(params))
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		if (p != "38" && p != "48") || i+1 >= len(params) {
+			out = append(out, p)
+			continue
+		}
+		base := 30
+		if p == "48" {
+			base = 40
+		}
+		var r, g, b int
+		switch {
+		case params[i+1] == "2" && i+4 < len(params):
+			r, g, b = atoiColor(params[i+2]), atoiColor(params[i+3]), atoiColor(params[i+4])
+			i += 4
+			if level == stdio.Color256 {
+				out = append(out, p, "5", strconv.Itoa(rgbToXterm256(r, g, b)))
+				continue
+			}
+		case params[i+1] == "5" && i+2 < len(params):
+			n := atoiColor(params[i+2])
+			i += 2
+			if level == stdio.Color256 {
+				out = append(out, p, "5", strconv.Itoa(n))
+				continue
+			}
+			r, g, b = xterm256ToRGB(n)
+		default:
+			out = append(out, p)
+			continue
+		}
+		out = append(out, strconv.Itoa(ansiColorCode(base, r, g, b)))
+	}
+	return "\033[" + strings.Join(out, ";") + "m"
+}
+
+func atoiColor(str string) int {
+	v, _ := strconv.Atoi(str)
+	return max(0, min(255, v))
+}
+
+// ansiPalette contains the 16 ANSI colors as displayed by xterm.
+var ansiPalette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// ansiColorCode returns the foreground (base 30) or background (base 40) code of the ANSI color with the closest hue. Colors of low saturation are mapped to black, gray or white.
+func ansiColorCode(base, r, g, b int) int {
+	maxC, minC := max(r, g, b), min(r, g, b)
+	var index int
+	if maxC-minC < 40 {
+		switch {
+		case maxC < 64:
+			index = 0
+		case maxC < 160:
+			index = 8
+		case maxC < 224:
+			index = 7
+		default:
+			index = 15
+		}
+	} else {
+		d := float64(maxC - minC)
+		var hue float64
+		switch maxC {
+		case r:
+			hue = float64(g-b) / d
+		case g:
+			hue = float64(b-r)/d + 2
+		default:
+			hue = float64(r-g)/d + 4
+		}
+		// red, yellow, green, cyan, blue and magenta in steps of 60 degrees
+		sector := (int(math.Round(hue)) + 6) % 6
+		index = [6]int{1, 3, 2, 6, 4, 5}[sector]
+		if maxC >= 230 {
+			index += 8
+		}
+	}
+	if index >= 8 {
+		return base + 60 + index - 8
+	}
+	return base + index
+}
+
+var xtermCubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// rgbToXterm256 returns the closest color of the xterm 256 color cube or grayscale ramp.
+func rgbToXterm256(r, g, b int) int {
+	if r == g && g == b {
+		switch {
+		case r < 8:
+			return 16
+		case r > 248:
+			return 231
+		default:
+			// the ramp ranges from 8 to 238 in steps of 10
+			return min(255, 232+(r-3)/10)
+		}
+	}
+	cube := func(v int) int {
+		best := 0
+		for i, l := range xtermCubeLevels {
+			if abs(l-v) < abs(xtermCubeLevels[best]-v) {
+				best = i
+			}
+		}
+		return best
+	}
+	return 16 + 36*cube(r) + 6*cube(g) + cube(b)
+}
+
+// xterm256ToRGB returns the RGB value of an xterm 256 color index.
+func xterm256ToRGB(n int) (int, int, int) {
+	switch {
+	case n < 16:
+		return ansiPalette[n][0], ansiPalette[n][1], ansiPalette[n][2]
+	case n < 232:
+		n -= 16
+		return xtermCubeLevels[n/36], xtermCubeLevels[n/6%6], xtermCubeLevels[n%6]
+	default:
+		v := 8 + 10*(n-232)
+		return v, v, v
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
 }
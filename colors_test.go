@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptColor(t *testing.T) {
+	assert.Equal(t, "\033[1;38;2;255;135;0m", adaptColor("\033[1;38;2;255;135;0m", stdio.ColorTrue))
+	assert.Equal(t, "\033[1;38;5;208m", adaptColor("\033[1;38;2;255;135;0m", stdio.Color256))
+	assert.Equal(t, "\033[1;93m", adaptColor("\033[1;38;2;255;240;0m", stdio.ColorBasic))
+	assert.Equal(t, "\033[48;5;240m", adaptColor("\033[48;2;88;88;88m", stdio.Color256))
+	assert.Equal(t, "\033[2;32;41m", adaptColor("\033[2;32;48;5;160m", stdio.ColorBasic))
+	assert.Equal(t, "\033[2;32m", adaptColor("\033[2;32m", stdio.ColorBasic))
+}
+
+func TestImportColor(t *testing.T) {
+	c := "\033[0m"
+	importColor(&c, "1;38;5;208", "WorkTime")
+	assert.Equal(t, "\033[1;38;5;208m", c)
+	importColor(&c, "bold bleu", "WorkTime")
+	assert.Equal(t, "\033[1;38;5;208m", c)
+}
+
+func TestShortenColors(t *testing.T) {
+	c := colorsDef{TripEntry: "\033[2;33m", CacheHint: "\033[1;30m"}
+	short := shortenColors(c)
+	assert.Equal(t, "2;33", short.TripEntry)
+	assert.Equal(t, "1;30", short.CacheHint)
+}
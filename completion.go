@@ -0,0 +1,382 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/alecthomas/kong"
+)
+
+// completionCommand is a visible command of the CLI model with its own flags and arguments.
+type completionCommand struct {
+	// Path is the command path like "reminders list", empty for the application itself.
+	Path     string
+	Help     string
+	Children []*kong.Node
+	Flags    []*kong.Flag
+	Args     []*kong.Positional
+}
+
+// completionCommands returns the application and all visible commands in depth-first order.
+func completionCommands(app *kong.Application) []completionCommand {
+	commands := make([]completionCommand, 0)
+	var walk func(node *kong.Node, path string)
+	walk = func(node *kong.Node, path string) {
+		cmd := completionCommand{Path: path, Help: node.Help, Args: node.Positional}
+		for _, child := range node.Children {
+			if child.Type == kong.CommandNode && !child.Hidden {
+				cmd.Children = append(cmd.Children, child)
+			}
+		}
+		for _, f := range node.Flags {
+			if !f.Hidden {
+				cmd.Flags = append(cmd.Flags, f)
+			}
+		}
+		commands = append(commands, cmd)
+		for _, child := range cmd.Children {
+			walk(child, strings.TrimSpace(path+" "+child.Name))
+		}
+	}
+	walk(app.Node, "")
+	return commands
+}
+
+// completionKind returns the type of values a flag or argument takes: enum, time, template, theme, file or dir. Values without completion tag return an empty string.
+func completionKind(v *kong.Value) string {
+	if len(v.Enum) > 0 {
+		return "enum"
+	}
+	return v.Tag.Get("completion")
+}
+
+// completionValues returns the fixed suggestions for a flag or argument.
+func completionValues(v *kong.Value) []string {
+	switch completionKind(v) {
+	case "enum":
+		return v.EnumSlice()
+	case "time":
+		// quarter hours cover target, leave and break times
+		times := make([]string, 0, 96)
+		for m := 0; m < 24*60; m += 15 {
+			times = append(times, fmt.Sprintf("%02d:%02d", m/60, m%60))
+		}
+		return times
+	case "theme":
+		return sortedKeys(builtinThemes)
+	case "template":
+		return sortedKeys(builtinShowTemplates)
+	case "config-key":
+		return userConfigKeys()
+	}
+	return nil
+}
+
+// userConfigKeys returns the keys of userconfig.json like "Reminders.Scheduler" or "Compliance.Rules.rest-period" for arguments tagged with completion:"config-key".
+func userConfigKeys() []string {
+	keys := make([]string, 0)
+	t := reflect.TypeOf(UserConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		keys = append(keys, name)
+		if f.Type.Kind() != reflect.Struct {
+			continue
+		}
+		for j := 0; j < f.Type.NumField(); j++ {
+			child, _, _ := strings.Cut(f.Type.Field(j).Tag.Get("json"), ",")
+			keys = append(keys, name+"."+child)
+		}
+	}
+	for _, r := range complianceRules {
+		keys = append(keys, "Compliance.Rules."+r.Name)
+	}
+	return keys
+}
+
+// takesValue returns true if a flag needs a value.
+func takesValue(f *kong.Flag) bool {
+	return !f.IsBool() && !f.IsCounter()
+}
+
+func flagNames(f *kong.Flag) []string {
+	names := []string{"--" + f.Name}
+	if f.Short != 0 {
+		names = append(names, "-"+string(f.Short))
+	}
+	return names
+}
+
+// writeBashCompletion writes a completion script for bash.
+func writeBashCompletion(w io.Writer, app *kong.Application) {
+	commands := completionCommands(app)
+	name := app.Name
+
+	paths := make([]string, 0, len(commands))
+	for _, cmd := range commands[1:] {
+		paths = append(paths, fmt.Sprintf("%q", cmd.Path))
+	}
+
+	fmt.Fprintf(w, "# bash completion for %s\n\n", name)
+	fmt.Fprintf(w, "_%s() {\n", name)
+	// COMP_WORDS is split at colons, so words are read from the line to complete times like 08:30
+	fmt.Fprintf(w, "    local line=\"${COMP_LINE:0:COMP_POINT}\" args\n")
+	fmt.Fprintf(w, "    read -ra args <<< \"$line\"\n")
+	fmt.Fprintf(w, "    [[ \"$line\" == *\" \" ]] && args+=(\"\")\n")
+	fmt.Fprintf(w, "    local cur=\"${args[-1]}\" prev=\"${args[-2]}\"\n")
+	fmt.Fprintf(w, "    local cmd=\"\" i\n")
+	fmt.Fprintf(w, "    for ((i = 1; i < ${#args[@]} - 1; i++)); do\n")
+	fmt.Fprintf(w, "        case \"${cmd:+$cmd }${args[i]}\" in\n")
+	fmt.Fprintf(w, "            %s) cmd=\"${cmd:+$cmd }${args[i]}\" ;;\n", strings.Join(paths, "|"))
+	fmt.Fprintf(w, "        esac\n")
+	fmt.Fprintf(w, "    done\n\n")
+
+	// flag values
+	fmt.Fprintf(w, "    case \"$cmd:$prev\" in\n")
+	for _, cmd := range commands {
+		prefix := fmt.Sprintf("%q", cmd.Path+":")
+		if len(cmd.Path) == 0 {
+			// flags of the application are valid for all commands
+			prefix = "*:"
+		}
+		for _, f := range cmd.Flags {
+			if !takesValue(f) {
+				continue
+			}
+			patterns := make([]string, 0, 2)
+			for _, n := range flagNames(f) {
+				patterns = append(patterns, prefix+n)
+			}
+			fmt.Fprintf(w, "        %s)\n", strings.Join(patterns, "|"))
+			switch completionKind(f.Value) {
+			case "file":
+				fmt.Fprintf(w, "            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+			case "dir":
+				fmt.Fprintf(w, "            COMPREPLY=($(compgen -d -- \"$cur\"))\n")
+			default:
+				if values := completionValues(f.Value); len(values) > 0 {
+					fmt.Fprintf(w, "            COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(values, " "))
+					// bash only replaces the part after the last colon
+					fmt.Fprintf(w, "            [[ \"$cur\" == *:* && \"$COMP_WORDBREAKS\" == *:* ]] && COMPREPLY=(\"${COMPREPLY[@]#\"${cur%%:*}:\"}\")\n")
+				}
+			}
+			fmt.Fprintf(w, "            return ;;\n")
+		}
+	}
+	fmt.Fprintf(w, "    esac\n\n")
+
+	// flags, commands and arguments
+	globalFlags := make([]string, 0)
+	for _, f := range commands[0].Flags {
+		globalFlags = append(globalFlags, flagNames(f)...)
+	}
+	fmt.Fprintf(w, "    local words=%q\n", strings.Join(globalFlags, " "))
+	fmt.Fprintf(w, "    case \"$cmd\" in\n")
+	for _, cmd := range commands {
+		flags := make([]string, 0)
+		for _, f := range cmd.Flags {
+			if len(cmd.Path) > 0 {
+				flags = append(flags, flagNames(f)...)
+			}
+		}
+		words := make([]string, 0)
+		for _, child := range cmd.Children {
+			words = append(words, child.Name)
+		}
+		for _, arg := range cmd.Args {
+			words = append(words, completionValues(arg)...)
+		}
+		fmt.Fprintf(w, "        %q)\n", cmd.Path)
+		fmt.Fprintf(w, "            [[ \"$cur\" == -* ]] && words+=%q || words=%q ;;\n", " "+strings.Join(flags, " "), strings.Join(words, " "))
+	}
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "    COMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	fmt.Fprintf(w, "}\n\n")
+	fmt.Fprintf(w, "complete -F _%s %s\n", name, name)
+}
+
+// zshDescribe returns a quoted item for _describe. Colons in the name are escaped as they separate the description.
+func zshDescribe(name, help string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(name, ":", "\\:")+":"+help, "'", "'\\''") + "'"
+}
+
+// writeZshCompletion writes a completion script for zsh.
+func writeZshCompletion(w io.Writer, app *kong.Application) {
+	commands := completionCommands(app)
+	name := app.Name
+
+	paths := make([]string, 0, len(commands))
+	for _, cmd := range commands[1:] {
+		paths = append(paths, fmt.Sprintf("%q", cmd.Path))
+	}
+
+	fmt.Fprintf(w, "#compdef %s\n\n", name)
+	fmt.Fprintf(w, "_%s() {\n", name)
+	fmt.Fprintf(w, "    local cur=\"${words[CURRENT]}\" prev=\"${words[CURRENT-1]}\"\n")
+	fmt.Fprintf(w, "    local cmd=\"\" i\n")
+	fmt.Fprintf(w, "    for ((i = 2; i < CURRENT; i++)); do\n")
+	fmt.Fprintf(w, "        case \"${cmd:+$cmd }${words[i]}\" in\n")
+	fmt.Fprintf(w, "            %s) cmd=\"${cmd:+$cmd }${words[i]}\" ;;\n", strings.Join(paths, "|"))
+	fmt.Fprintf(w, "        esac\n")
+	fmt.Fprintf(w, "    done\n\n")
+
+	fmt.Fprintf(w, "    local -a values\n")
+	fmt.Fprintf(w, "    case \"$cmd:$prev\" in\n")
+	for _, cmd := range commands {
+		prefix := fmt.Sprintf("%q", cmd.Path+":")
+		if len(cmd.Path) == 0 {
+			prefix = "*:"
+		}
+		for _, f := range cmd.Flags {
+			if !takesValue(f) {
+				continue
+			}
+			patterns := make([]string, 0, 2)
+			for _, n := range flagNames(f) {
+				patterns = append(patterns, prefix+n)
+			}
+			fmt.Fprintf(w, "        %s)\n", strings.Join(patterns, "|"))
+			switch completionKind(f.Value) {
+			case "file":
+				fmt.Fprintf(w, "            _files\n")
+			case "dir":
+				fmt.Fprintf(w, "            _files -/\n")
+			default:
+				if values := completionValues(f.Value); len(values) > 0 {
+					fmt.Fprintf(w, "            values=(%s)\n", strings.Join(values, " "))
+					fmt.Fprintf(w, "            compadd -a values\n")
+				}
+			}
+			fmt.Fprintf(w, "            return ;;\n")
+		}
+	}
+	fmt.Fprintf(w, "    esac\n\n")
+
+	fmt.Fprintf(w, "    local -a flags=(\n")
+	for _, f := range commands[0].Flags {
+		for _, n := range flagNames(f) {
+			fmt.Fprintf(w, "        %s\n", zshDescribe(n, f.Help))
+		}
+	}
+	fmt.Fprintf(w, "    )\n")
+	fmt.Fprintf(w, "    local -a subcommands\n")
+	fmt.Fprintf(w, "    case \"$cmd\" in\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "        %q)\n", cmd.Path)
+		if len(cmd.Path) > 0 && len(cmd.Flags) > 0 {
+			fmt.Fprintf(w, "            flags+=(\n")
+			for _, f := range cmd.Flags {
+				for _, n := range flagNames(f) {
+					fmt.Fprintf(w, "                %s\n", zshDescribe(n, f.Help))
+				}
+			}
+			fmt.Fprintf(w, "            )\n")
+		}
+		if len(cmd.Children) > 0 {
+			fmt.Fprintf(w, "            subcommands=(\n")
+			for _, child := range cmd.Children {
+				fmt.Fprintf(w, "                %s\n", zshDescribe(child.Name, child.Help))
+			}
+			fmt.Fprintf(w, "            )\n")
+		}
+		for _, arg := range cmd.Args {
+			if values := completionValues(arg); len(values) > 0 {
+				fmt.Fprintf(w, "            values+=(%s)\n", strings.Join(values, " "))
+			}
+		}
+		fmt.Fprintf(w, "            ;;\n")
+	}
+	fmt.Fprintf(w, "    esac\n\n")
+	fmt.Fprintf(w, "    if [[ \"$cur\" == -* ]]; then\n")
+	fmt.Fprintf(w, "        _describe -t flags flag flags\n")
+	fmt.Fprintf(w, "    else\n")
+	fmt.Fprintf(w, "        _describe -t commands command subcommands\n")
+	fmt.Fprintf(w, "        compadd -a values\n")
+	fmt.Fprintf(w, "    fi\n")
+	fmt.Fprintf(w, "}\n\n")
+	fmt.Fprintf(w, "_%s \"$@\"\n", name)
+}
+
+func fishQuote(str string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(str, "\\", "\\\\"), "'", "\\'") + "'"
+}
+
+// writeFishCompletion writes a completion script for fish.
+func writeFishCompletion(w io.Writer, app *kong.Application) {
+	commands := completionCommands(app)
+	name := app.Name
+
+	paths := make([]string, 0, len(commands))
+	for _, cmd := range commands[1:] {
+		paths = append(paths, fishQuote(cmd.Path))
+	}
+
+	fmt.Fprintf(w, "# fish completion for %s\n\n", name)
+	fmt.Fprintf(w, "function __%s_cmd\n", name)
+	fmt.Fprintf(w, "    set -l cmd ''\n")
+	fmt.Fprintf(w, "    for w in (commandline -opc)[2..-1]\n")
+	fmt.Fprintf(w, "        set -l candidate (string trim -- \"$cmd $w\")\n")
+	fmt.Fprintf(w, "        if contains -- $candidate %s\n", strings.Join(paths, " "))
+	fmt.Fprintf(w, "            set cmd $candidate\n")
+	fmt.Fprintf(w, "        end\n")
+	fmt.Fprintf(w, "    end\n")
+	fmt.Fprintf(w, "    echo $cmd\n")
+	fmt.Fprintf(w, "end\n\n")
+	fmt.Fprintf(w, "function __%s_using\n", name)
+	fmt.Fprintf(w, "    set -l cmd (__%s_cmd)\n", name)
+	fmt.Fprintf(w, "    test \"$cmd\" = \"$argv[1]\"\n")
+	fmt.Fprintf(w, "end\n\n")
+	fmt.Fprintf(w, "complete -c %s -f\n", name)
+
+	for _, cmd := range commands {
+		condition := ""
+		if len(cmd.Path) > 0 {
+			condition = fmt.Sprintf(" -n %s", fishQuote(fmt.Sprintf("__%s_using %s", name, fishQuote(cmd.Path))))
+		}
+		for _, child := range cmd.Children {
+			fmt.Fprintf(w, "complete -c %s -n %s -a %s -d %s\n", name, fishQuote(fmt.Sprintf("__%s_using %s", name, fishQuote(cmd.Path))), child.Name, fishQuote(child.Help))
+		}
+		for _, arg := range cmd.Args {
+			if values := completionValues(arg); len(values) > 0 {
+				fmt.Fprintf(w, "complete -c %s%s -a %s -d %s\n", name, condition, fishQuote(strings.Join(values, " ")), fishQuote(arg.Help))
+			}
+		}
+		for _, f := range cmd.Flags {
+			opts := fmt.Sprintf(" -l %s", f.Name)
+			if f.Short != 0 {
+				opts += fmt.Sprintf(" -s %c", f.Short)
+			}
+			if takesValue(f) {
+				switch completionKind(f.Value) {
+				case "file":
+					opts += " -r -F"
+				case "dir":
+					opts += " -x -a '(__fish_complete_directories)'"
+				default:
+					opts += " -x"
+					if values := completionValues(f.Value); len(values) > 0 {
+						opts += " -a " + fishQuote(strings.Join(values, " "))
+					}
+				}
+			}
+			fmt.Fprintf(w, "complete -c %s%s%s -d %s\n", name, condition, opts, fishQuote(f.Help))
+		}
+	}
+}
+
+func cmdCompletion() error {
+	switch cli.Completion.Shell {
+	case "bash":
+		writeBashCompletion(os.Stdout, cliModel)
+	case "zsh":
+		writeZshCompletion(os.Stdout, cliModel)
+	case "fish":
+		writeFishCompletion(os.Stdout, cliModel)
+	default:
+		return fmt.Errorf("unsupported shell %q", cli.Completion.Shell)
+	}
+	return nil
+}
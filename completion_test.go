@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCLIModel(t *testing.T) *kong.Application {
+	parser, err := kong.New(&cli, cliOptions...)
+	require.NoError(t, err)
+	return parser.Model
+}
+
+func TestCompletionCommands(t *testing.T) {
+	commands := completionCommands(testCLIModel(t))
+	paths := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		paths = append(paths, cmd.Path)
+	}
+	assert.Equal(t, "", paths[0])
+	assert.Contains(t, paths, "reminders list")
+	assert.Contains(t, paths, "completion")
+	// hidden commands are not completed
+	assert.NotContains(t, paths, "reminders notify")
+}
+
+func TestBashCompletion(t *testing.T) {
+	var sb strings.Builder
+	writeBashCompletion(&sb, testCLIModel(t))
+	script := sb.String()
+	assert.Contains(t, script, `"show:"--target-time|"show:"-t)`)
+	assert.Contains(t, script, `compgen -W "compact default minimal"`)
+	assert.Contains(t, script, `*:--dump-dir)`)
+	assert.Contains(t, script, "complete -F _gohome gohome\n")
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	complete := func(line string) string {
+		out, err := exec.Command("bash", "-c", script+`
+COMP_LINE="$1"; COMP_POINT=${#COMP_LINE}; _gohome; echo "${COMPREPLY[*]}"`, "gohome", line).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+	assert.Equal(t, "list clear watch", complete("gohome reminders "))
+	assert.Equal(t, "in out trip", complete("gohome -v clock "))
+	assert.Equal(t, "08:00 08:15 08:30 08:45", complete("gohome show -t 08"))
+	assert.Equal(t, "--debug --dump-dir --dry-run", complete("gohome clock --d"))
+}
+
+func TestZshAndFishCompletion(t *testing.T) {
+	var sb strings.Builder
+	writeZshCompletion(&sb, testCLIModel(t))
+	assert.True(t, strings.HasPrefix(sb.String(), "#compdef gohome\n"))
+	assert.Contains(t, sb.String(), `'--tomorrow-target:plan tomorrow'\''s earliest start`)
+
+	sb.Reset()
+	writeFishCompletion(&sb, testCLIModel(t))
+	assert.Contains(t, sb.String(), `complete -c gohome -n '__gohome_using \'reminders\'' -a list -d 'List pending reminders'`)
+	assert.Contains(t, sb.String(), `complete -c gohome -n '__gohome_using \'export\'' -l output -s o -r -F -d 'output file, defaults to stdout'`)
+}
+
+func TestManPage(t *testing.T) {
+	var sb strings.Builder
+	writeManPage(&sb, testCLIModel(t))
+	page := sb.String()
+	assert.True(t, strings.HasPrefix(page, ".TH GOHOME 1 "))
+	assert.Contains(t, page, ".SS \"gohome clock <action>\"\n")
+	assert.Contains(t, page, "\\fB\\-\\-target\\-time\\fR, \\fB\\-t\\fR \\fITARGET_TIME\\fR\n")
+	assert.NotContains(t, page, "notify")
+}
+
+func TestUserConfigKeys(t *testing.T) {
+	keys := userConfigKeys()
+	assert.Contains(t, keys, "TargetTime")
+	assert.Contains(t, keys, "Reminders.Scheduler")
+	assert.Contains(t, keys, "Credentials.PasswordCommand")
+	assert.Contains(t, keys, "Compliance.Rules.rest-period")
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+const (
+	// ComplianceSeverityOff disables a rule.
+	ComplianceSeverityOff = "off"
+	// ComplianceSeverityInfo reports a finding as information.
+	ComplianceSeverityInfo = "info"
+	// ComplianceSeverityWarning reports a finding as warning.
+	ComplianceSeverityWarning = "warning"
+	// ComplianceSeverityError reports a finding as error.
+	ComplianceSeverityError = "error"
+
+	// complianceRuleRestPeriod is the name of the rule for rest periods between shifts.
+	complianceRuleRestPeriod = "rest-period"
+
+	// complianceAverageWeeks is the reference period of the weekly average (six months).
+	complianceAverageWeeks = 24
+	// complianceMinBreak is the minimum duration of an interruption to count as break.
+	complianceMinBreak = 15 * time.Minute
+	// complianceBreakMargin is the minimum work time before and after a break.
+	complianceBreakMargin = 30 * time.Minute
+)
+
+var (
+	// complianceSeverityLevels are the log levels of findings. Unknown severities are logged as info.
+	complianceSeverityLevels = map[string]slog.Level{
+		ComplianceSeverityError:   slog.LevelError,
+		ComplianceSeverityWarning: slog.LevelWarn,
+		ComplianceSeverityInfo:    slog.LevelInfo,
+	}
+
+	// complianceRules are all rules of the German working hours act checked over the history.
+	complianceRules = []complianceRule{
+		{Name: complianceRuleRestPeriod, Severity: ComplianceSeverityWarning, Limit: 11 * time.Hour, Check: checkRestPeriod},
+		{Name: "daily-max", Severity: ComplianceSeverityError, Limit: 10 * time.Hour, Check: checkDailyMax},
+		{Name: "weekly-average", Severity: ComplianceSeverityWarning, Limit: 48 * time.Hour, Check: checkWeeklyAverage},
+		{Name: "break-placement", Severity: ComplianceSeverityInfo, Limit: 6 * time.Hour, Check: checkBreakPlacement},
+	}
+)
+
+// ComplianceConfig overrides severities and limits of compliance rules by rule name.
+type ComplianceConfig struct {
+	Rules map[string]ComplianceRuleConfig `json:"Rules,omitempty"`
+}
+
+// ComplianceRuleConfig overrides the defaults of a single rule.
+type ComplianceRuleConfig struct {
+	// Severity is one of "off", "info", "warning" or "error".
+	Severity string `json:"Severity,omitempty"`
+	// Limit in format "15:04" like "11:00" for the rest period.
+	Limit string `json:"Limit,omitempty"`
+}
+
+type complianceRule struct {
+	Name     string
+	Severity string
+	Limit    time.Duration
+	// Check returns all violations in the given days sorted by date.
+	Check func(days []DaySummary, limit time.Duration) []ComplianceFinding
+}
+
+// ComplianceFinding is a violation of a compliance rule.
+type ComplianceFinding struct {
+	Rule     string
+	Severity string
+	Date     time.Time
+	Message  string
+}
+
+// complianceRuleFor returns the rule with user overrides applied.
+func complianceRuleFor(conf ComplianceConfig, rule complianceRule) (complianceRule, error) {
+	override, ok := conf.Rules[rule.Name]
+	if !ok {
+		return rule, nil
+	}
+	if len(override.Severity) > 0 {
+		switch override.Severity {
+		case ComplianceSeverityOff, ComplianceSeverityInfo, ComplianceSeverityWarning, ComplianceSeverityError:
+			rule.Severity = override.Severity
+		default:
+			return rule, fmt.Errorf("invalid severity %q for compliance rule %q", override.Severity, rule.Name)
+		}
+	}
+	if len(override.Limit) > 0 {
+		limit, err := parseDurationHHMM(override.Limit)
+		if err != nil {
+			return rule, fmt.Errorf("invalid limit for compliance rule %q: %s", rule.Name, err.Error())
+		}
+		rule.Limit = limit
+	}
+	return rule, nil
+}
+
+// CheckCompliance returns the findings of all enabled rules sorted by date.
+func CheckCompliance(conf ComplianceConfig, days []DaySummary) ([]ComplianceFinding, error) {
+	findings := make([]ComplianceFinding, 0)
+	for _, r := range complianceRules {
+		rule, err := complianceRuleFor(conf, r)
+		if err != nil {
+			return nil, err
+		}
+		if rule.Severity == ComplianceSeverityOff {
+			continue
+		}
+		for _, f := range rule.Check(days, rule.Limit) {
+			f.Rule = rule.Name
+			f.Severity = rule.Severity
+			findings = append(findings, f)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Date.Before(findings[j].Date) })
+	return findings, nil
+}
+
+// EarliestStart returns the earliest start after the rest period following the given leave time, or false if the rule is disabled.
+func EarliestStart(conf ComplianceConfig, leave time.Time) (time.Time, bool) {
+	for _, r := range complianceRules {
+		if r.Name != complianceRuleRestPeriod {
+			continue
+		}
+		rule, err := complianceRuleFor(conf, r)
+		if err != nil || rule.Severity == ComplianceSeverityOff {
+			return time.Time{}, false
+		}
+		return leave.Add(rule.Limit), true
+	}
+	return time.Time{}, false
+}
+
+func checkRestPeriod(days []DaySummary, limit time.Duration) []ComplianceFinding {
+	findings := make([]ComplianceFinding, 0)
+	for i := 1; i < len(days); i++ {
+		prev, cur := days[i-1], days[i]
+		if prev.Incomplete || len(prev.Blocks) == 0 || len(cur.Blocks) == 0 {
+			continue
+		}
+		end := prev.Blocks[len(prev.Blocks)-1].End
+		if rest := cur.Blocks[0].Start.Sub(end); rest < limit {
+			findings = append(findings, ComplianceFinding{Date: cur.Date, Message: fmt.Sprintf("only %s rest since %s, earliest allowed start was %s",
+				formatDurationMinutes(rest), end.Format("Mon 15:04"), end.Add(limit).Format("15:04"))})
+		}
+	}
+	return findings
+}
+
+func checkDailyMax(days []DaySummary, limit time.Duration) []ComplianceFinding {
+	findings := make([]ComplianceFinding, 0)
+	for _, s := range days {
+		if !s.Incomplete && s.WorkTime > limit {
+			findings = append(findings, ComplianceFinding{Date: s.Date, Message: fmt.Sprintf("worked %s, only %s are allowed",
+				formatDurationMinutes(s.WorkTime), formatDurationMinutes(limit))})
+		}
+	}
+	return findings
+}
+
+// checkWeeklyAverage reports weeks whose average over the preceding reference period exceeds the limit. Weeks without days in the history are not considered.
+func checkWeeklyAverage(days []DaySummary, limit time.Duration) []ComplianceFinding {
+	type week struct {
+		Start    time.Time
+		Last     time.Time
+		WorkTime time.Duration
+	}
+	weeks := make([]week, 0)
+	for _, s := range days {
+		if s.Incomplete {
+			continue
+		}
+		start := s.Date.AddDate(0, 0, -((int(s.Date.Weekday()) + 6) % 7))
+		if len(weeks) == 0 || !weeks[len(weeks)-1].Start.Equal(start) {
+			weeks = append(weeks, week{Start: start})
+		}
+		weeks[len(weeks)-1].Last = s.Date
+		weeks[len(weeks)-1].WorkTime += s.WorkTime
+	}
+
+	findings := make([]ComplianceFinding, 0)
+	for i, w := range weeks {
+		var sum time.Duration
+		count := 0
+		for j := i; j >= 0 && !weeks[j].Start.Before(w.Start.AddDate(0, 0, -7*(complianceAverageWeeks-1))); j-- {
+			sum += weeks[j].WorkTime
+			count++
+		}
+		if avg := sum / time.Duration(count); avg > limit {
+			_, isoWeek := w.Start.ISOWeek()
+			findings = append(findings, ComplianceFinding{Date: w.Last, Message: fmt.Sprintf("average of %s per week over %d weeks until week %02d exceeds %s",
+				formatDurationMinutes(avg), count, isoWeek, formatDurationMinutes(limit))})
+		}
+	}
+	return findings
+}
+
+// checkBreakPlacement reports continuous work longer than the limit and breaks directly after arrival or before leaving. Interruptions shorter than 15 minutes do not count as break.
+func checkBreakPlacement(days []DaySummary, limit time.Duration) []ComplianceFinding {
+	findings := make([]ComplianceFinding, 0)
+	for _, s := range days {
+		if s.Incomplete || len(s.Blocks) == 0 {
+			continue
+		}
+
+		segmentStart := s.Blocks[0].Start
+		dayEnd := s.Blocks[len(s.Blocks)-1].End
+		for i, b := range s.Blocks {
+			isLast := i == len(s.Blocks)-1
+			if !isLast && s.Blocks[i+1].Start.Sub(b.End) < complianceMinBreak {
+				continue
+			}
+			if d := b.End.Sub(segmentStart); d > limit {
+				findings = append(findings, ComplianceFinding{Date: s.Date, Message: fmt.Sprintf("worked %s without break from %s to %s",
+					formatDurationMinutes(d), segmentStart.Format("15:04"), b.End.Format("15:04"))})
+			}
+			if isLast {
+				break
+			}
+
+			breakStart, breakEnd := b.End, s.Blocks[i+1].Start
+			if breakStart.Sub(s.Blocks[0].Start) < complianceBreakMargin {
+				findings = append(findings, ComplianceFinding{Date: s.Date, Message: fmt.Sprintf("break %s-%s directly after arrival",
+					breakStart.Format("15:04"), breakEnd.Format("15:04"))})
+			} else if dayEnd.Sub(breakEnd) < complianceBreakMargin {
+				findings = append(findings, ComplianceFinding{Date: s.Date, Message: fmt.Sprintf("break %s-%s directly before leaving",
+					breakStart.Format("15:04"), breakEnd.Format("15:04"))})
+			}
+			segmentStart = breakEnd
+		}
+	}
+	return findings
+}
+
+// complianceDays returns the summaries of all days between from and to from the history. Today is replaced by the given entries if present.
+func complianceDays(h *History, from, to time.Time, today []Entry) []DaySummary {
+	days := make([]DaySummary, 0)
+	for _, date := range h.Dates(from, to) {
+		if len(today) > 0 && isToday(date) {
+			continue
+		}
+		day, _ := h.Get(date)
+		// the target time has no influence on compliance
+		s, err := SummarizeDay(date, day.Entries, 0)
+		if err != nil {
+			worktimeLog.Debug("skip day", "date", date.Format(historyDateLayout), "err", err.Error())
+			continue
+		}
+		days = append(days, s)
+	}
+	if len(today) > 0 {
+		if s, err := SummarizeDay(time.Now(), today, 0); err == nil {
+			days = append(days, s)
+		}
+	}
+	return days
+}
+
+// printComplianceWarnings prints all findings of today.
+func printComplianceWarnings(conf ComplianceConfig, entries []Entry) {
+	h, err := ReadHistory()
+	if err != nil {
+		stdio.Warn("read history: %s", err.Error())
+		return
+	}
+	findings, err := todayComplianceFindings(conf, h, entries)
+	if err != nil {
+		stdio.Warn("%s", err.Error())
+		return
+	}
+	for _, f := range findings {
+		printComplianceFinding(f)
+	}
+}
+
+// todayComplianceFindings returns the findings of today for the given entries with respect to previous days from the history.
+func todayComplianceFindings(conf ComplianceConfig, h *History, entries []Entry) ([]ComplianceFinding, error) {
+	now := time.Now()
+	days := complianceDays(h, now.AddDate(0, 0, -7*complianceAverageWeeks), now, entries)
+	findings, err := CheckCompliance(conf, days)
+	if err != nil {
+		return nil, err
+	}
+	today := make([]ComplianceFinding, 0)
+	for _, f := range findings {
+		if isToday(f.Date) {
+			today = append(today, f)
+		}
+	}
+	return today, nil
+}
+
+func printComplianceFinding(f ComplianceFinding) {
+	stdio.Log(complianceSeverityLevels[f.Severity], "%s: %s", f.Rule, f.Message)
+}
+
+func cmdCompliance() error {
+	from, to, err := parsePeriod(cli.Compliance.Period)
+	if err != nil {
+		return err
+	}
+	if len(cli.Compliance.From) > 0 || len(cli.Compliance.To) > 0 {
+		fromStr := cli.Compliance.From
+		if len(fromStr) == 0 {
+			fromStr = from.Format(historyDateLayout)
+		}
+		if from, to, err = parseDateRange(fromStr, cli.Compliance.To); err != nil {
+			return err
+		}
+	}
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		stdio.Warn("read user config failed: %s", err.Error())
+	}
+
+	h, err := ReadHistory()
+	if err != nil {
+		return fmt.Errorf("read history: %s", err.Error())
+	}
+	if cli.Compliance.Fetch {
+		if err := fetchHistory(h, from, to); err != nil {
+			return err
+		}
+	}
+
+	// include the previous day for rest periods and the reference period for weekly averages
+	days := complianceDays(h, from.AddDate(0, 0, -7*complianceAverageWeeks), to, nil)
+	findings, err := CheckCompliance(usrConf.Compliance, days)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, f := range findings {
+		if f.Date.Before(from) {
+			continue
+		}
+		counts[f.Severity]++
+		stdio.Println("%s  %-7s  %-15s  %s", f.Date.Format("Mon 2006-01-02"), f.Severity, f.Rule, f.Message)
+	}
+	if len(counts) == 0 {
+		stdio.Println("no violations between %s and %s", from.Format(historyDateLayout), to.Format(historyDateLayout))
+		return nil
+	}
+	stdio.Println("%s", "-----------------------------------------------------")
+	stdio.Println("%d errors, %d warnings, %d infos", counts[ComplianceSeverityError], counts[ComplianceSeverityWarning], counts[ComplianceSeverityInfo])
+	return nil
+}
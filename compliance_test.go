@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testComplianceDays(t *testing.T, days ...[]Entry) []DaySummary {
+	summaries := make([]DaySummary, 0, len(days))
+	for _, entries := range days {
+		s, err := SummarizeDay(entries[0].Time, entries, 8*time.Hour)
+		require.NoError(t, err)
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+func TestCheckCompliance(t *testing.T) {
+	monday := time.Date(2026, time.September, 7, 0, 0, 0, 0, time.Local)
+	at := func(day, hour, minute int) time.Time {
+		return monday.AddDate(0, 0, day).Add(dur(hour, minute))
+	}
+	days := testComplianceDays(t,
+		[]Entry{{EntryTypeCome, at(0, 9, 0)}, {EntryTypeLeave, at(0, 20, 30)}},
+		[]Entry{{EntryTypeCome, at(1, 7, 0)}, {EntryTypeLeave, at(1, 7, 20)}, {EntryTypeCome, at(1, 8, 0)}, {EntryTypeLeave, at(1, 13, 0)}},
+		[]Entry{{EntryTypeCome, at(2, 8, 0)}, {EntryTypeLeave, at(2, 12, 0)}, {EntryTypeCome, at(2, 12, 10)}, {EntryTypeLeave, at(2, 16, 30)}},
+	)
+
+	findings, err := CheckCompliance(ComplianceConfig{}, days)
+	require.NoError(t, err)
+	require.Len(t, findings, 5)
+
+	assert.Equal(t, "daily-max", findings[0].Rule)
+	assert.Equal(t, ComplianceSeverityError, findings[0].Severity)
+	assert.Equal(t, "worked 11:30, only 10:00 are allowed", findings[0].Message)
+	assert.Equal(t, "worked 11:30 without break from 09:00 to 20:30", findings[1].Message)
+
+	assert.Equal(t, "rest-period", findings[2].Rule)
+	assert.Equal(t, monday.AddDate(0, 0, 1), findings[2].Date)
+	assert.Equal(t, "only 10:30 rest since Mon 20:30, earliest allowed start was 07:30", findings[2].Message)
+	assert.Equal(t, "break 07:20-08:00 directly after arrival", findings[3].Message)
+
+	assert.Equal(t, "worked 08:30 without break from 08:00 to 16:30", findings[4].Message, "a 10 minute interruption is no break")
+
+	findings, err = CheckCompliance(ComplianceConfig{Rules: map[string]ComplianceRuleConfig{
+		"daily-max":       {Severity: ComplianceSeverityOff},
+		"rest-period":     {Limit: "10:00"},
+		"break-placement": {Severity: ComplianceSeverityWarning, Limit: "09:00"},
+	}}, days)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	for _, f := range findings {
+		assert.Equal(t, "break-placement", f.Rule)
+		assert.Equal(t, ComplianceSeverityWarning, f.Severity)
+	}
+
+	_, err = CheckCompliance(ComplianceConfig{Rules: map[string]ComplianceRuleConfig{"daily-max": {Severity: "fatal"}}}, days)
+	assert.Error(t, err)
+}
+
+func TestCheckWeeklyAverage(t *testing.T) {
+	monday := time.Date(2026, time.September, 7, 0, 0, 0, 0, time.Local)
+	var days []DaySummary
+	for week := 0; week < 2; week++ {
+		for day := 0; day < 5; day++ {
+			date := monday.AddDate(0, 0, 7*week+day)
+			days = append(days, DaySummary{Date: date, WorkTime: dur(9, 0) + time.Duration(week)*dur(2, 0)})
+		}
+	}
+
+	findings := checkWeeklyAverage(days, 48*time.Hour)
+	require.Len(t, findings, 1)
+	assert.Equal(t, monday.AddDate(0, 0, 11), findings[0].Date)
+	assert.Equal(t, "average of 50:00 per week over 2 weeks until week 38 exceeds 48:00", findings[0].Message)
+}
+
+func TestEarliestStart(t *testing.T) {
+	leave := time.Date(2026, time.September, 7, 20, 42, 0, 0, time.Local)
+	start, ok := EarliestStart(ComplianceConfig{}, leave)
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, time.September, 8, 7, 42, 0, 0, time.Local), start)
+
+	_, ok = EarliestStart(ComplianceConfig{Rules: map[string]ComplianceRuleConfig{"rest-period": {Severity: ComplianceSeverityOff}}}, leave)
+	assert.False(t, ok)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/user"
 	"path"
@@ -144,6 +145,9 @@ func GetMatrixConfig() (MatrixConfig, error) {
 }
 
 func enterMatrixConfig() (MatrixConfig, error) {
+	if cli.NonInteractive {
+		return MatrixConfig{}, fmt.Errorf("no Matrix configuration found in %s and prompting is disabled by --non-interactive, run gohome once interactively", getConfigDir())
+	}
 	stdio.Println("Please enter your Matrix configuration below:")
 	host, err := stdio.ReadLineWithPrompt("Host> ")
 	if err != nil {
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+func cmdCorrect() error {
+	var req CorrectionRequest
+	if !cli.Correct.List {
+		var err error
+		req, err = parseCorrectionRequest(cli.Correct.Date, cli.Correct.Come, cli.Correct.Leave, cli.Correct.Reason)
+		if err != nil {
+			return err
+		}
+	}
+
+	matrixConfig, err := getMatrixConfigWithPassword()
+	if err != nil {
+		return err
+	}
+
+	client, err := NewMatrixClient(matrixConfig)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if cli.Correct.List {
+		return listCorrections(client)
+	}
+	return requestCorrection(client, req, cli.Correct.DryRun)
+}
+
+// parseCorrectionRequest builds a correction request from command line values. An empty date is today.
+func parseCorrectionRequest(dateStr, comeStr, leaveStr, reason string) (CorrectionRequest, error) {
+	if len(dateStr) == 0 {
+		dateStr = time.Now().Format("2006-01-02")
+	}
+	date, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+	if err != nil {
+		return CorrectionRequest{}, fmt.Errorf("failed to parse date: %s", err.Error())
+	}
+	if date.After(time.Now()) {
+		return CorrectionRequest{}, fmt.Errorf("cannot request corrections for future days")
+	}
+
+	req := CorrectionRequest{Date: date, Reason: reason}
+	if len(comeStr) > 0 {
+		if req.Come, err = parseTimeOnDay(date, comeStr); err != nil {
+			return CorrectionRequest{}, fmt.Errorf("failed to parse come time: %s", err.Error())
+		}
+	}
+	if len(leaveStr) > 0 {
+		if req.Leave, err = parseTimeOnDay(date, leaveStr); err != nil {
+			return CorrectionRequest{}, fmt.Errorf("failed to parse leave time: %s", err.Error())
+		}
+	}
+	if req.Come.IsZero() && req.Leave.IsZero() {
+		return CorrectionRequest{}, fmt.Errorf("at least one of --come and --leave is required")
+	}
+	if !req.Come.IsZero() && !req.Leave.IsZero() && !req.Leave.After(req.Come) {
+		return CorrectionRequest{}, fmt.Errorf("leave time must be after come time")
+	}
+	return req, nil
+}
+
+func parseTimeOnDay(day time.Time, str string) (time.Time, error) {
+	t, err := time.Parse("15:04", str)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, time.Local), nil
+}
+
+func requestCorrection(client *MatrixClient, req CorrectionRequest, dryRun bool) error {
+	values, err := client.RequestCorrection(req, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		stdio.Println("dry-run: would request correction for %s with form values:", req.Date.Format("2006-01-02"))
+		for _, k := range keys {
+			value := values.Get(k)
+			if k == "uniqueToken" || k == "javax.faces.ViewState" {
+				value = redactedValue
+			}
+			stdio.Println("  %s = %s", k, value)
+		}
+		return nil
+	}
+
+	if err := InvalidateCache(); err != nil {
+		stdio.Warn("invalidate cache failed: %s", err.Error())
+	}
+	stdio.Println("correction request for %s submitted", req.Date.Format("2006-01-02"))
+	return nil
+}
+
+func listCorrections(client *MatrixClient) error {
+	corrections, err := client.GetCorrections()
+	if err != nil {
+		return err
+	}
+	if len(corrections) == 0 {
+		stdio.Println("no correction requests")
+		return nil
+	}
+
+	for _, c := range corrections {
+		stdio.Println("%-10s  %-9s %s %s(%s)%s", c.Date, c.State, c.Description, colors.BreakInfo, c.Status, colorEnd)
+	}
+	return nil
+}
+
+// printEntryGaps nudges the user to file correction requests for missing bookings.
+func printEntryGaps(entries []Entry) {
+	gaps := FindEntryGaps(entries)
+	for _, gap := range gaps {
+		day := gap.Before
+		if day.IsZero() {
+			day = gap.After
+		}
+		flag := "--come"
+		if gap.Type == EntryTypeLeave {
+			flag = "--leave"
+		}
+		stdio.Warn("%s, file a correction request with: gohome correct --date %s %s <15:04> --reason <reason>", gap.String(), day.Format("2006-01-02"), flag)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCorrectionRequest(t *testing.T) {
+	req, err := parseCorrectionRequest("2026-10-14", "08:05", "16:40", "forgot badge")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.October, 14, 8, 5, 0, 0, time.Local), req.Come)
+	assert.Equal(t, time.Date(2026, time.October, 14, 16, 40, 0, 0, time.Local), req.Leave)
+	assert.Equal(t, "forgot badge", req.Reason)
+
+	req, err = parseCorrectionRequest("", "00:00", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, truncateDay(time.Now()), req.Date)
+
+	_, err = parseCorrectionRequest("2026-10-14", "", "", "")
+	assert.Error(t, err)
+	_, err = parseCorrectionRequest("2026-10-14", "16:40", "08:05", "")
+	assert.Error(t, err)
+	_, err = parseCorrectionRequest("14.10.2026", "08:05", "", "")
+	assert.Error(t, err)
+	_, err = parseCorrectionRequest(time.Now().AddDate(0, 0, 2).Format("2006-01-02"), "08:05", "", "")
+	assert.Error(t, err)
+}
+
+func TestRequestCorrection(t *testing.T) {
+	useTempConfigDir(t)
+	fm := newFakeMatrix(t)
+	client, err := NewMatrixClient(fm.Config())
+	require.NoError(t, err)
+
+	req, err := parseCorrectionRequest("2026-10-14", "08:05", "16:40", "forgot badge")
+	require.NoError(t, err)
+
+	require.NoError(t, requestCorrection(client, req, true))
+	assert.Empty(t, fm.Corrections)
+
+	require.NoError(t, requestCorrection(client, req, false))
+	require.Len(t, fm.Corrections, 1)
+	assert.Equal(t, "14.10.2026", fm.Corrections[0]["mainbody:correction:date_input"])
+	assert.Equal(t, "08:05", fm.Corrections[0]["mainbody:correction:come"])
+	assert.Equal(t, "16:40", fm.Corrections[0]["mainbody:correction:leave"])
+	assert.Equal(t, "forgot badge", fm.Corrections[0]["mainbody:correction:reason"])
+	assert.NotContains(t, fm.Corrections[0], "mainbody:correction:cancel")
+
+	corrections, err := client.GetCorrections()
+	require.NoError(t, err)
+	assert.Equal(t, []Correction{
+		{Date: "01.10.2026", Description: "Booking correction", Status: "Approved", State: CorrectionApproved},
+		{Date: "14.10.2026", Description: "Booking correction", Status: "Pending", State: CorrectionPending},
+	}, corrections)
+}
+
+func TestMatrixDateLayout(t *testing.T) {
+	assert.Equal(t, "02.01.2006", matrixDateLayout("dd.MM.yyyy", "en"))
+	assert.Equal(t, "2006-01-02", matrixDateLayout("yyyy-MM-dd", "de"))
+	assert.Equal(t, "01/02/2006", matrixDateLayout("", "en-US"))
+	assert.Equal(t, "02.01.2006", matrixDateLayout("", ""))
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+const (
+	// passwordEnvVar is always checked for the Matrix password before the configured sources.
+	passwordEnvVar = "GOHOME_PASSWORD"
+)
+
+// CredentialConfig defines where the Matrix password is read from if it is not stored in matrix.json.
+type CredentialConfig struct {
+	// PasswordFile contains the password in the first line and must only be accessible by the current user.
+	PasswordFile string `json:"PasswordFile,omitempty"`
+	// PasswordCommand like "pass show matrix" prints the password in the first line.
+	PasswordCommand string `json:"PasswordCommand,omitempty"`
+	// Helper is a credential helper like "git credential-libsecret" that is called with "get" as in git.
+	Helper string `json:"Helper,omitempty"`
+}
+
+// passwordSource returns the Matrix password or false if it has none.
+type passwordSource interface {
+	Name() string
+	Password(config MatrixConfig) (string, bool, error)
+}
+
+// passwordSources returns the non-interactive sources in the order they are tried.
+func passwordSources(conf CredentialConfig) []passwordSource {
+	sources := silentPasswordSources(conf)
+	if len(conf.PasswordCommand) > 0 {
+		sources = append(sources, commandPasswordSource{Command: conf.PasswordCommand})
+	}
+	if len(conf.Helper) > 0 {
+		sources = append(sources, helperPasswordSource{Command: conf.Helper})
+	}
+	return sources
+}
+
+// silentPasswordSources returns the sources that never run a password manager, which might ask for a passphrase.
+func silentPasswordSources(conf CredentialConfig) []passwordSource {
+	sources := []passwordSource{envPasswordSource{Var: passwordEnvVar}}
+	if len(conf.PasswordFile) > 0 {
+		sources = append(sources, filePasswordSource{File: conf.PasswordFile})
+	}
+	return sources
+}
+
+// lookupPassword returns the password of the first source that has one. Failing sources are reported instead of being skipped.
+func lookupPassword(config MatrixConfig, sources []passwordSource) (string, bool, error) {
+	for _, src := range sources {
+		pass, ok, err := src.Password(config)
+		if err != nil {
+			return "", false, fmt.Errorf("%s: %s", src.Name(), err.Error())
+		}
+		if ok {
+			stdio.Debug("use password from %s", src.Name())
+			return pass, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// lookupMatrixPassword tries all configured password sources and prompts for the password if none has one and prompting is allowed.
+func lookupMatrixPassword(config MatrixConfig) (string, error) {
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		stdio.Warn("read user config failed: %s", err.Error())
+	}
+	sources := passwordSources(usrConf.Credentials)
+	pass, ok, err := lookupPassword(config, sources)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return pass, nil
+	}
+
+	if cli.NonInteractive {
+		names := make([]string, 0, len(sources))
+		for _, src := range sources {
+			names = append(names, src.Name())
+		}
+		return "", fmt.Errorf("no password found in %s and prompting is disabled by --non-interactive", strings.Join(names, ", "))
+	}
+	stdio.Println("Please enter Matrix password (it will not be stored locally):")
+	return stdio.ReadPasswordWithPrompt("> ")
+}
+
+// envPasswordSource reads the password from an environment variable.
+type envPasswordSource struct {
+	Var string
+}
+
+func (s envPasswordSource) Name() string {
+	return "environment variable " + s.Var
+}
+
+func (s envPasswordSource) Password(MatrixConfig) (string, bool, error) {
+	pass := os.Getenv(s.Var)
+	return pass, len(pass) > 0, nil
+}
+
+// filePasswordSource reads the first line of a file that must be owned by the current user and not be accessible by others.
+type filePasswordSource struct {
+	File string
+}
+
+func (s filePasswordSource) Name() string {
+	return "password file " + s.File
+}
+
+func (s filePasswordSource) Password(MatrixConfig) (string, bool, error) {
+	fi, err := os.Stat(s.File)
+	if err != nil {
+		return "", false, err
+	}
+	if !fi.Mode().IsRegular() {
+		return "", false, fmt.Errorf("not a regular file")
+	}
+	if perm := fi.Mode().Perm(); perm&0077 != 0 {
+		return "", false, fmt.Errorf("file is accessible by other users (mode %04o), run chmod 600 %s", perm, s.File)
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		return "", false, fmt.Errorf("file is owned by uid %d instead of the current user", st.Uid)
+	}
+
+	data, err := os.ReadFile(s.File)
+	if err != nil {
+		return "", false, err
+	}
+	pass := firstLine(data)
+	if len(pass) == 0 {
+		return "", false, fmt.Errorf("file is empty")
+	}
+	return pass, true, nil
+}
+
+// commandPasswordSource runs a command like "pass show matrix" or "gopass show -o matrix" and uses the first line of its output.
+type commandPasswordSource struct {
+	Command string
+}
+
+func (s commandPasswordSource) Name() string {
+	return "password command"
+}
+
+func (s commandPasswordSource) Password(MatrixConfig) (string, bool, error) {
+	cmd := exec.Command("sh", "-c", s.Command)
+	// password managers may ask for a passphrase on stderr
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false, fmt.Errorf("%q failed: %s", s.Command, err.Error())
+	}
+	pass := firstLine(out)
+	if len(pass) == 0 {
+		return "", false, fmt.Errorf("%q printed no password", s.Command)
+	}
+	return pass, true, nil
+}
+
+// helperPasswordSource asks a git credential helper for the password of the Matrix host and user.
+type helperPasswordSource struct {
+	Command string
+}
+
+func (s helperPasswordSource) Name() string {
+	return "credential helper"
+}
+
+func (s helperPasswordSource) Password(config MatrixConfig) (string, bool, error) {
+	var input bytes.Buffer
+	if u, err := url.Parse(config.Host); err == nil && len(u.Host) > 0 {
+		fmt.Fprintf(&input, "protocol=%s\nhost=%s\n", u.Scheme, u.Host)
+	}
+	fmt.Fprintf(&input, "username=%s\n\n", config.User)
+
+	cmd := exec.Command("sh", "-c", s.Command+" get")
+	cmd.Stdin = &input
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false, fmt.Errorf("%q failed: %s", s.Command, err.Error())
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok && key == "password" && len(value) > 0 {
+			return value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func firstLine(data []byte) string {
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSuffix(line, "\r")
+}
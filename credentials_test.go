@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePasswordSource(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(file, []byte("s3cret\nignored\n"), 0644))
+	src := filePasswordSource{File: file}
+
+	_, _, err := src.Password(MatrixConfig{})
+	assert.EqualError(t, err, "file is accessible by other users (mode 0644), run chmod 600 "+file)
+
+	require.NoError(t, os.Chmod(file, 0600))
+	pass, ok, err := src.Password(MatrixConfig{})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "s3cret", pass)
+}
+
+func TestCommandPasswordSource(t *testing.T) {
+	pass, ok, err := commandPasswordSource{Command: `printf 's3cret\nmetadata'`}.Password(MatrixConfig{})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "s3cret", pass)
+
+	_, _, err = commandPasswordSource{Command: "exit 1"}.Password(MatrixConfig{})
+	assert.EqualError(t, err, `"exit 1" failed: exit status 1`)
+}
+
+func TestHelperPasswordSource(t *testing.T) {
+	helper := filepath.Join(t.TempDir(), "helper")
+	require.NoError(t, os.WriteFile(helper, []byte(`#!/bin/sh
+[ "$1" = get ] || exit 1
+input=$(cat)
+case "$input" in
+*host=matrix.example.com*username=jdoe*) printf 'username=jdoe\npassword=s3cret\n' ;;
+esac
+`), 0700))
+	src := helperPasswordSource{Command: helper}
+
+	pass, ok, err := src.Password(MatrixConfig{Host: "https://matrix.example.com", User: "jdoe"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "s3cret", pass)
+
+	_, ok, err = src.Password(MatrixConfig{Host: "https://matrix.example.com", User: "other"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLookupPassword(t *testing.T) {
+	t.Setenv(passwordEnvVar, "")
+	sources := passwordSources(CredentialConfig{PasswordCommand: "echo fromcommand"})
+	pass, ok, err := lookupPassword(MatrixConfig{}, sources)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "fromcommand", pass)
+
+	t.Setenv(passwordEnvVar, "fromenv")
+	pass, _, err = lookupPassword(MatrixConfig{}, sources)
+	require.NoError(t, err)
+	assert.Equal(t, "fromenv", pass)
+
+	t.Setenv(passwordEnvVar, "")
+	_, _, err = lookupPassword(MatrixConfig{}, passwordSources(CredentialConfig{PasswordFile: "/nonexistent/password"}))
+	assert.ErrorContains(t, err, "password file /nonexistent/password: ")
+
+	_, ok, err = lookupPassword(MatrixConfig{}, passwordSources(CredentialConfig{}))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSilentPasswordSources(t *testing.T) {
+	sources := silentPasswordSources(CredentialConfig{PasswordFile: "/tmp/password", PasswordCommand: "pass show matrix", Helper: "git credential-libsecret"})
+	assert.Equal(t, []passwordSource{envPasswordSource{Var: passwordEnvVar}, filePasswordSource{File: "/tmp/password"}}, sources)
+}
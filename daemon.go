@@ -0,0 +1,416 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+const (
+	// daemonRPCService is the name of the JSON-RPC service offered on the daemon socket.
+	daemonRPCService = "GoHome"
+	// daemonTickInterval is the interval for recomputing the status and firing reminders.
+	daemonTickInterval = time.Minute
+	// daemonReminderGrace is the max age of a due reminder that is still fired, e.g. after suspend.
+	daemonReminderGrace = 5 * time.Minute
+	// daemonMaxWait limits the duration of a single Wait call.
+	daemonMaxWait = 10 * time.Minute
+)
+
+// DaemonStatus is the state published by the daemon.
+type DaemonStatus struct {
+	// Version is incremented on every change of the status.
+	Version     uint64    `json:"version"`
+	Status      *Status   `json:"status,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	LastRefresh time.Time `json:"last_refresh,omitzero"`
+}
+
+// daemon keeps today's entries in memory and periodically refreshes them from Matrix.
+type daemon struct {
+	fetch      func() ([]Entry, time.Duration, error)
+	targetTime time.Duration
+	reminders  ReminderConfig
+	// onChange is called with every new status, e.g. to emit D-Bus signals.
+	onChange func(DaemonStatus)
+
+	// refreshMutex serializes fetches so concurrent Refresh calls do not log in to Matrix at the same time.
+	refreshMutex sync.Mutex
+	// emitMutex keeps onChange calls in the order of the status versions.
+	emitMutex      sync.Mutex
+	emittedVersion uint64
+
+	mutex            sync.Mutex
+	changed          *sync.Cond
+	entries          []Entry
+	flexiTimeBalance time.Duration
+	fetchErr         error
+	lastFetch        time.Time
+	status           DaemonStatus
+	pending          []Reminder
+}
+
+func newDaemon(fetch func() ([]Entry, time.Duration, error), targetTime time.Duration, reminders ReminderConfig) *daemon {
+	d := &daemon{fetch: fetch, targetTime: targetTime, reminders: reminders.WithDefaults()}
+	d.changed = sync.NewCond(&d.mutex)
+	return d
+}
+
+// Refresh fetches the entries from Matrix and recomputes the status. Callers that wait for a running refresh get its result instead of fetching again.
+func (d *daemon) Refresh() DaemonStatus {
+	requested := time.Now()
+	d.refreshMutex.Lock()
+	defer d.refreshMutex.Unlock()
+
+	d.mutex.Lock()
+	if d.lastFetch.After(requested) {
+		status := d.status
+		d.mutex.Unlock()
+		return status
+	}
+	d.mutex.Unlock()
+
+	fetchTime := time.Now()
+	entries, flexiTimeBalance, err := d.fetch()
+
+	d.mutex.Lock()
+	d.lastFetch = fetchTime
+	d.fetchErr = err
+	if err != nil {
+		stdio.Warn("refresh failed: %s", err.Error())
+	} else {
+		d.entries = entries
+		d.flexiTimeBalance = flexiTimeBalance
+		d.status.LastRefresh = time.Now()
+		d.updateReminders()
+	}
+	status := d.update()
+	d.mutex.Unlock()
+
+	d.emit(status)
+	return status
+}
+
+// Tick recomputes the status with the current time and fires due reminders.
+func (d *daemon) Tick(now time.Time) DaemonStatus {
+	d.mutex.Lock()
+	var due []Reminder
+	for i, r := range d.pending {
+		if r.Fired || r.Time.After(now) {
+			continue
+		}
+		d.pending[i].Fired = true
+		if now.Sub(r.Time) > daemonReminderGrace {
+			stdio.Debug("skip outdated reminder %q at %s", r.Milestone, r.Time.Format("15:04"))
+			continue
+		}
+		due = append(due, r)
+	}
+	status := d.update()
+	d.mutex.Unlock()
+
+	// notifiers may take several seconds and must not block status requests
+	for _, r := range due {
+		stdio.Debug("fire reminder %q", r.Milestone)
+		if err := NotifyReminder(d.reminders, r); err != nil {
+			stdio.Warn("reminder %q: %s", r.Milestone, err.Error())
+		}
+	}
+	d.emit(status)
+	return status
+}
+
+// Status returns the current status.
+func (d *daemon) Status() DaemonStatus {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.status
+}
+
+// Wait blocks until the status version differs from the given one or the timeout elapses.
+func (d *daemon) Wait(version uint64, timeout time.Duration) DaemonStatus {
+	timer := time.AfterFunc(timeout, func() {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.changed.Broadcast()
+	})
+	defer timer.Stop()
+
+	deadline := time.Now().Add(timeout)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for d.status.Version == version && time.Now().Before(deadline) {
+		d.changed.Wait()
+	}
+	return d.status
+}
+
+// Reminders returns the reminders of today.
+func (d *daemon) Reminders() []Reminder {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return append([]Reminder{}, d.pending...)
+}
+
+// Current returns the status computed for the current time without publishing it as change.
+func (d *daemon) Current() DaemonStatus {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.compute()
+}
+
+// update recomputes the status and wakes up waiting callers. The mutex must be held by the caller, the new status is passed to emit after unlocking.
+func (d *daemon) update() DaemonStatus {
+	version := d.status.Version
+	d.status = d.compute()
+	d.status.Version = version + 1
+	d.changed.Broadcast()
+	return d.status
+}
+
+// emit passes the status to onChange unless a newer status has already been emitted. The mutex must not be held by the caller.
+func (d *daemon) emit(status DaemonStatus) {
+	if d.onChange == nil {
+		return
+	}
+	d.emitMutex.Lock()
+	defer d.emitMutex.Unlock()
+	if status.Version <= d.emittedVersion {
+		return
+	}
+	d.emittedVersion = status.Version
+	d.onChange(status)
+}
+
+// compute returns a copy of the status with work times computed for the current time. The mutex must be held by the caller.
+func (d *daemon) compute() DaemonStatus {
+	status := d.status
+	status.Status = nil
+	status.Error = ""
+	if d.fetchErr != nil {
+		status.Error = d.fetchErr.Error()
+	}
+	if len(d.entries) > 0 {
+		s, err := ComputeStatus(d.entries, d.flexiTimeBalance, d.targetTime, nil)
+		if err != nil {
+			status.Error = err.Error()
+		} else {
+			s.CacheTime = status.LastRefresh
+			status.Status = s
+		}
+	}
+	return status
+}
+
+// updateReminders computes the reminders for the current entries and keeps the fired state of unchanged reminders. The mutex must be held by the caller.
+func (d *daemon) updateReminders() {
+	if len(d.entries) == 0 || d.entries[len(d.entries)-1].Type != EntryTypeCome {
+		d.pending = nil
+		return
+	}
+	_, startTime, breakTime, err := ComputeWorkTime(d.entries)
+	if err != nil {
+		stdio.Warn("compute reminders: %s", err.Error())
+		return
+	}
+	reminders, err := ComputeReminders(d.reminders.Milestones, startTime, breakTime, d.targetTime)
+	if err != nil {
+		stdio.Warn("compute reminders: %s", err.Error())
+		return
+	}
+
+	for i := range reminders {
+		reminders[i].Scheduler = "daemon"
+		for _, old := range d.pending {
+			if old.Milestone == reminders[i].Milestone && old.Time.Equal(reminders[i].Time) {
+				reminders[i].Fired = old.Fired
+			}
+		}
+	}
+	d.pending = reminders
+}
+
+// Run refreshes and ticks in the configured intervals until stop is closed.
+func (d *daemon) Run(refreshInterval time.Duration, stop <-chan struct{}) {
+	d.Refresh()
+	d.Tick(time.Now())
+
+	refresh := time.NewTicker(refreshInterval)
+	defer refresh.Stop()
+	tick := time.NewTicker(daemonTickInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-refresh.C:
+			d.Refresh()
+		case now := <-tick.C:
+			d.Tick(now)
+		}
+	}
+}
+
+// DaemonRPC is the JSON-RPC interface of the daemon socket.
+type DaemonRPC struct {
+	d *daemon
+}
+
+// DaemonWaitArgs are the arguments of DaemonRPC.Wait.
+type DaemonWaitArgs struct {
+	// Version is the last known status version.
+	Version        uint64 `json:"version"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// Status returns the current status.
+func (r *DaemonRPC) Status(_ struct{}, reply *DaemonStatus) error {
+	*reply = r.d.Status()
+	return nil
+}
+
+// Refresh fetches entries from Matrix and returns the new status.
+func (r *DaemonRPC) Refresh(_ struct{}, reply *DaemonStatus) error {
+	*reply = r.d.Refresh()
+	return nil
+}
+
+// Wait returns the status as soon as it differs from the given version.
+func (r *DaemonRPC) Wait(args DaemonWaitArgs, reply *DaemonStatus) error {
+	timeout := time.Duration(args.TimeoutSeconds) * time.Second
+	if timeout <= 0 || timeout > daemonMaxWait {
+		timeout = daemonMaxWait
+	}
+	*reply = r.d.Wait(args.Version, timeout)
+	return nil
+}
+
+// Reminders returns today's reminders.
+func (r *DaemonRPC) Reminders(_ struct{}, reply *[]Reminder) error {
+	*reply = r.d.Reminders()
+	return nil
+}
+
+// serveDaemonSocket accepts JSON-RPC connections on a Unix socket until the listener is closed.
+func serveDaemonSocket(d *daemon, listener net.Listener) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(daemonRPCService, &DaemonRPC{d: d}); err != nil {
+		return err
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+func listenDaemonSocket(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("daemon is already running on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// cachingMatrixFetcher returns a fetch function for the daemon that also updates the cache for other commands.
+func cachingMatrixFetcher(matrixConfig MatrixConfig) func() ([]Entry, time.Duration, error) {
+	return func() ([]Entry, time.Duration, error) {
+		entries, flexiTimeBalance, err := FetchMatrixEntries(matrixConfig)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := WriteCache(entries, flexiTimeBalance); err != nil {
+			stdio.Warn("write cache failed: %s", err.Error())
+		}
+		return entries, flexiTimeBalance, nil
+	}
+}
+
+func defaultDaemonSocket() string {
+	return filepath.Join(xdg.RuntimeDir, "gohome.sock")
+}
+
+func cmdDaemon() error {
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		stdio.Warn("read user config failed: %s", err.Error())
+	}
+	targetTime, err := parseTargetTime(cli.Daemon.TargetTime, usrConf)
+	if err != nil {
+		return err
+	}
+	matrixConfig, err := getMatrixConfigWithPassword()
+	if err != nil {
+		return err
+	}
+
+	d := newDaemon(cachingMatrixFetcher(matrixConfig), targetTime, usrConf.Reminders)
+	if cli.Daemon.NoReminders {
+		d.reminders.Milestones = nil
+	}
+
+	if !cli.Daemon.NoDBus {
+		bus, err := exportDaemonDBus(d)
+		if err != nil {
+			stdio.Warn("D-Bus interface not available: %s", err.Error())
+		} else {
+			defer bus.Close()
+			stdio.Info("exported D-Bus interface %s", daemonDBusName)
+		}
+	}
+
+	socketPath := cli.Daemon.Socket
+	if len(socketPath) == 0 {
+		socketPath = defaultDaemonSocket()
+	}
+	listener, err := listenDaemonSocket(socketPath)
+	if err != nil {
+		return fmt.Errorf("listen on socket: %s", err.Error())
+	}
+	defer os.Remove(socketPath)
+	go func() {
+		if err := serveDaemonSocket(d, listener); err != nil {
+			stdio.Debug("socket closed: %s", err.Error())
+		}
+	}()
+	stdio.Info("listening on %s", socketPath)
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	d.Run(cli.Daemon.Refresh, stop)
+	listener.Close()
+	return nil
+}
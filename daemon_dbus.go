@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+const (
+	daemonDBusName      = "io.github.sbreitf1.GoHome"
+	daemonDBusPath      = dbus.ObjectPath("/io/github/sbreitf1/GoHome")
+	daemonDBusInterface = "io.github.sbreitf1.GoHome"
+	// daemonDBusSignal is emitted with the status as JSON whenever it changes.
+	daemonDBusSignal = daemonDBusInterface + ".StatusChanged"
+)
+
+// daemonDBus is exported on the session bus. Status values are passed as JSON to keep the interface stable.
+type daemonDBus struct {
+	d *daemon
+}
+
+// Status returns the current status as JSON.
+func (obj daemonDBus) Status() (string, *dbus.Error) {
+	return marshalDaemonStatus(obj.d.Status())
+}
+
+// Refresh fetches entries from Matrix and returns the new status as JSON.
+func (obj daemonDBus) Refresh() (string, *dbus.Error) {
+	return marshalDaemonStatus(obj.d.Refresh())
+}
+
+func marshalDaemonStatus(status DaemonStatus) (string, *dbus.Error) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	return string(data), nil
+}
+
+// exportDaemonDBus registers the daemon on the session bus and emits a StatusChanged signal on every change.
+func exportDaemonDBus(d *daemon) (*dbus.Conn, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+
+	obj := daemonDBus{d: d}
+	if err := conn.Export(obj, daemonDBusPath, daemonDBusInterface); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	node := &introspect.Node{
+		Name: string(daemonDBusPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			{
+				Name:    daemonDBusInterface,
+				Methods: introspect.Methods(obj),
+				Signals: []introspect.Signal{{Name: "StatusChanged", Args: []introspect.Arg{{Name: "status", Type: "s"}}}},
+			},
+		},
+	}
+	if err := conn.Export(introspect.NewIntrospectable(node), daemonDBusPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reply, err := conn.RequestName(daemonDBusName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		conn.Close()
+		return nil, fmt.Errorf("name %s is already taken", daemonDBusName)
+	}
+
+	d.onChange = func(status DaemonStatus) {
+		data, err := json.Marshal(status)
+		if err != nil {
+			stdio.Warn("marshal status: %s", err.Error())
+			return
+		}
+		if err := conn.Emit(daemonDBusPath, daemonDBusSignal, string(data)); err != nil {
+			stdio.Debug("emit signal: %s", err.Error())
+		}
+	}
+	return conn, nil
+}
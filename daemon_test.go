@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDaemon(entries []Entry) *daemon {
+	return newDaemon(func() ([]Entry, time.Duration, error) {
+		if entries == nil {
+			return nil, 0, fmt.Errorf("matrix unavailable")
+		}
+		return entries, 90 * time.Minute, nil
+	}, 8*time.Hour, ReminderConfig{})
+}
+
+func TestDaemonRefresh(t *testing.T) {
+	d := newTestDaemon([]Entry{{Type: EntryTypeCome, Time: time.Now().Add(-2 * time.Hour)}})
+	var changes int
+	d.onChange = func(DaemonStatus) { changes++ }
+
+	status := d.Refresh()
+	require.NotNil(t, status.Status)
+	assert.Empty(t, status.Error)
+	assert.Equal(t, uint64(1), status.Version)
+	assert.Equal(t, 90*time.Minute, status.Status.FlexiTimeBalance)
+	assert.True(t, status.Status.Ticking())
+	assert.Len(t, d.Reminders(), 2)
+
+	status = d.Tick(time.Now())
+	assert.Equal(t, uint64(2), status.Version)
+	assert.Equal(t, 2, changes)
+}
+
+func TestDaemonRefreshError(t *testing.T) {
+	d := newTestDaemon(nil)
+	status := d.Refresh()
+	assert.Nil(t, status.Status)
+	assert.Equal(t, "matrix unavailable", status.Error)
+}
+
+func TestDaemonConcurrentRefresh(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	d := newDaemon(func() ([]Entry, time.Duration, error) {
+		fetches.Add(1)
+		<-release
+		return []Entry{{Type: EntryTypeCome, Time: time.Now().Add(-time.Hour)}}, 0, nil
+	}, 8*time.Hour, ReminderConfig{})
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.Refresh()
+		}()
+	}
+	// all callers wait for the first fetch, the others are merged into a single second fetch
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestDaemonFireReminders(t *testing.T) {
+	dir := t.TempDir()
+	hookFile := filepath.Join(dir, "hook.txt")
+	d := newTestDaemon([]Entry{{Type: EntryTypeCome, Time: time.Now().Add(-time.Hour)}})
+	d.reminders = ReminderConfig{
+		Notifiers:   []string{"hook"},
+		HookCommand: `echo "$GOHOME_MILESTONE" >> ` + shellQuote(hookFile),
+		Milestones:  []ReminderMilestone{{Name: "2h", WorkTime: "02:00", Message: "two hours"}},
+	}.WithDefaults()
+	d.Refresh()
+
+	reminders := d.Reminders()
+	require.Len(t, reminders, 1)
+	d.Tick(reminders[0].Time.Add(time.Minute))
+	d.Tick(reminders[0].Time.Add(2 * time.Minute))
+
+	// refresh keeps the fired state of unchanged reminders
+	d.Refresh()
+	assert.True(t, d.Reminders()[0].Fired)
+	d.Tick(reminders[0].Time.Add(3 * time.Minute))
+
+	data, err := os.ReadFile(hookFile)
+	require.NoError(t, err)
+	assert.Equal(t, "2h\n", string(data))
+}
+
+func TestDaemonSocket(t *testing.T) {
+	d := newTestDaemon([]Entry{{Type: EntryTypeCome, Time: time.Now().Add(-time.Hour)}})
+	d.Refresh()
+
+	socketPath := filepath.Join(t.TempDir(), "gohome.sock")
+	listener, err := listenDaemonSocket(socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+	go serveDaemonSocket(d, listener)
+
+	_, err = listenDaemonSocket(socketPath)
+	assert.Error(t, err, "second daemon must not take over the socket")
+
+	client, err := jsonrpc.Dial("unix", socketPath)
+	require.NoError(t, err)
+	defer client.Close()
+
+	var status DaemonStatus
+	require.NoError(t, client.Call("GoHome.Status", struct{}{}, &status))
+	require.NotNil(t, status.Status)
+	assert.Equal(t, EntryTypeCome, status.Status.State)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		d.Tick(time.Now())
+	}()
+	var changed DaemonStatus
+	require.NoError(t, client.Call("GoHome.Wait", DaemonWaitArgs{Version: status.Version, TimeoutSeconds: 5}, &changed))
+	assert.Equal(t, status.Version+1, changed.Version)
+
+	var reminders []Reminder
+	require.NoError(t, client.Call("GoHome.Reminders", struct{}{}, &reminders))
+	assert.Len(t, reminders, 2)
+}
@@ -0,0 +1,79 @@
+#!/bin/bash
+# Functions shared by the package scripts. Everything is built from the local module cache or
+# vendor directory, so run "go mod download" or "go mod vendor" once while online.
+
+DISTRIB_DIR=$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)
+SOURCE_DIR=$(cd "$DISTRIB_DIR/.." && pwd)
+
+export GOPROXY=off
+export GOFLAGS=-trimpath
+if [[ -f $SOURCE_DIR/vendor/modules.txt ]]; then
+    GOFLAGS="$GOFLAGS -mod=vendor"
+fi
+
+# detect_version prints the version given as argument or of the git tag on HEAD.
+detect_version() {
+    local version=$1
+    if [[ -z $version ]]; then
+        version=$(git -C "$SOURCE_DIR" describe --exact-match --tags HEAD 2>/dev/null | sed 's/v//g')
+        if [[ -z $version ]]; then
+            echo "no version detected! git tag like 'v0.0.0' on current commit is required." >&2
+            return 1
+        fi
+    fi
+    if [[ ! "$version" =~ ^[0-9]+\.[0-9]+\.[0-9]+$ ]]; then
+        echo "invalid version! must be like '0.0.0'." >&2
+        return 1
+    fi
+    echo "$version"
+}
+
+# maintainer prints the author of the latest commit, or $MAINTAINER if set.
+maintainer() {
+    if [[ -n $MAINTAINER ]]; then
+        echo "$MAINTAINER"
+    else
+        git -C "$SOURCE_DIR" log -1 --format='%an <%ae>'
+    fi
+}
+
+# release_date prints the date of the latest commit in the given date format to keep builds reproducible.
+release_date() {
+    local epoch=${SOURCE_DATE_EPOCH:-$(git -C "$SOURCE_DIR" log -1 --format=%ct)}
+    LC_ALL=C date -u -d "@$epoch" "$1"
+}
+
+# release_notes prints the commit subjects since the previous tag, one per line.
+release_notes() {
+    local prev
+    prev=$(git -C "$SOURCE_DIR" describe --tags --abbrev=0 HEAD^ 2>/dev/null || true)
+    git -C "$SOURCE_DIR" log --no-merges --format=%s ${prev:+$prev..}HEAD
+}
+
+# stage builds gohome for an architecture and installs all package files below a root directory.
+stage() {
+    local version=$1 arch=$2 root=$3
+
+    echo "compile application for $arch"
+    local ldflags="-X main.version=$version -X main.buildDate=$(release_date +%Y-%m-%dT%H:%M:%SZ)"
+    mkdir -p "$root/usr/bin"
+    CGO_ENABLED=0 GOOS=linux GOARCH=$arch go build -ldflags "$ldflags" -o "$root/usr/bin/gohome" "$SOURCE_DIR"
+
+    echo "generate shell completions and man page"
+    # completions are generated with a binary for the build host
+    local host
+    host=$(mktemp -d)
+    CGO_ENABLED=0 go build -ldflags "$ldflags" -o "$host/gohome" "$SOURCE_DIR"
+    mkdir -p "$root/usr/share/bash-completion/completions" "$root/usr/share/zsh/vendor-completions" "$root/usr/share/fish/vendor_completions.d" "$root/usr/share/man/man1"
+    "$host/gohome" completion bash > "$root/usr/share/bash-completion/completions/gohome"
+    "$host/gohome" completion zsh > "$root/usr/share/zsh/vendor-completions/_gohome"
+    "$host/gohome" completion fish > "$root/usr/share/fish/vendor_completions.d/gohome.fish"
+    "$host/gohome" man | gzip -9n > "$root/usr/share/man/man1/gohome.1.gz"
+    rm -rf "$host"
+
+    echo "install systemd user units and documentation"
+    install -Dm644 "$DISTRIB_DIR/systemd/gohome-daemon.service" "$root/usr/lib/systemd/user/gohome-daemon.service"
+    install -Dm644 "$DISTRIB_DIR/systemd/gohome-reminders.service" "$root/usr/lib/systemd/user/gohome-reminders.service"
+    install -Dm644 "$DISTRIB_DIR/copyright" "$root/usr/share/doc/gohome/copyright"
+    install -Dm644 "$SOURCE_DIR/README.md" "$root/usr/share/doc/gohome/README.md"
+}
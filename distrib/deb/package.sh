@@ -1,40 +1,51 @@
 #!/bin/bash
+# usage: package.sh [version] [arch...]
+# Builds gohome_<version>_<arch>.deb for amd64 and arm64 or the given architectures.
 
 set -e
 
-VERSION=$1
+source "$(dirname "$0")/../common.sh"
 
-if [[ -z $VERSION ]]; then
-    VERSION=$(git describe --exact-match --tags HEAD | sed 's/v//g')
-    if [[ -z $VERSION ]]; then
-        echo "no version detected! git tag like 'v0.0.0' on current commit is required."
-        exit 1
-    fi
-fi
-
-if [[ ! "$VERSION" =~ ^[0-9]+\.[0-9]+\.[0-9]+$ ]]; then
-    echo "invalid version! must be like '0.0.0'."
-    exit 1
+VERSION=$(detect_version "$1")
+shift || true
+ARCHS=("$@")
+if [[ ${#ARCHS[@]} -eq 0 ]]; then
+    ARCHS=(amd64 arm64)
 fi
 
 echo "package version v${VERSION}"
 
-echo "compile application"
-mkdir -p ./gohome/usr/bin
-CGO_ENABLED=0 GOARCH=amd64 go build -o ./gohome/usr/bin/gohome ../../
+for ARCH in "${ARCHS[@]}"; do
+    ROOT=./gohome_${ARCH}
+    rm -rf "$ROOT"
+    stage "$VERSION" "$ARCH" "$ROOT"
+
+    echo "prepare package files"
+    {
+        echo "gohome (${VERSION}) unstable; urgency=medium"
+        echo
+        release_notes | sed 's/^/  * /'
+        echo
+        echo " -- $(maintainer)  $(release_date -R)"
+    } | gzip -9n > "$ROOT/usr/share/doc/gohome/changelog.Debian.gz"
 
-INST_SIZE=$(du -ks ./gohome/usr | cut -f 1)
+    INST_SIZE=$(du -ks "$ROOT/usr" | cut -f 1)
 
-echo "prepare package files"
-mkdir -p ./gohome/DEBIAN
-echo "Package: gohome
+    mkdir -p "$ROOT/DEBIAN"
+    echo "Package: gohome
 Version: ${VERSION}
-Maintainer: sbreitf1
-Architecture: amd64
+Section: utils
+Priority: optional
+Maintainer: $(maintainer)
+Architecture: ${ARCH}
 Installed-Size: $INST_SIZE
+Recommends: at, libnotify-bin
 Homepage: https://github.com/sbreitf1/gohome
-Description: Calculates your leave time from Matrix entries." > ./gohome/DEBIAN/control
-
-dpkg-deb --build gohome gohome_${VERSION}_amd64.deb
-
-rm -rf ./gohome
+Description: Calculates your leave time from Matrix entries.
+ Shows the current work time and possible leave times from the Matrix time
+ recording system, sends go-home reminders and exports timesheets." > "$ROOT/DEBIAN/control"
+    (cd "$ROOT" && find usr -type f -exec md5sum {} +) > "$ROOT/DEBIAN/md5sums"
+
+    dpkg-deb --root-owner-group --build "$ROOT" "gohome_${VERSION}_${ARCH}.deb"
+    rm -rf "$ROOT"
+done
@@ -0,0 +1,31 @@
+# The package is assembled from files staged by package.sh, so there is nothing to build here.
+%global debug_package %{nil}
+%global __strip /bin/true
+%global _build_id_links none
+
+Name:       gohome
+Version:    %{gohome_version}
+Release:    1
+Summary:    Calculates your leave time from Matrix entries
+License:    MIT
+URL:        https://github.com/sbreitf1/gohome
+Recommends: at
+Recommends: libnotify
+
+%description
+Shows the current work time and possible leave times from the Matrix time
+recording system, sends go-home reminders and exports timesheets.
+
+%install
+cp -a %{gohome_stage}/. %{buildroot}/
+
+%files
+/usr/bin/gohome
+/usr/lib/systemd/user/gohome-daemon.service
+/usr/lib/systemd/user/gohome-reminders.service
+/usr/share/bash-completion/completions/gohome
+/usr/share/zsh/vendor-completions/_gohome
+/usr/share/fish/vendor_completions.d/gohome.fish
+/usr/share/man/man1/gohome.1.gz
+%license /usr/share/doc/gohome/copyright
+%doc /usr/share/doc/gohome/README.md
@@ -0,0 +1,47 @@
+#!/bin/bash
+# usage: package.sh [version] [arch...]
+# Builds gohome-<version>-1.<arch>.rpm for amd64 and arm64 or the given architectures.
+
+set -e
+
+source "$(dirname "$0")/../common.sh"
+
+VERSION=$(detect_version "$1")
+shift || true
+ARCHS=("$@")
+if [[ ${#ARCHS[@]} -eq 0 ]]; then
+    ARCHS=(amd64 arm64)
+fi
+
+echo "package version v${VERSION}"
+
+TOPDIR=$(mktemp -d)
+trap 'rm -rf "$TOPDIR"' EXIT
+
+SPEC=$TOPDIR/gohome.spec
+{
+    cat "$(dirname "$0")/gohome.spec"
+    echo
+    echo "%changelog"
+    echo "* $(release_date '+%a %b %d %Y') $(maintainer) - ${VERSION}-1"
+    release_notes | sed 's/^/- /; s/%/%%/g'
+} > "$SPEC"
+
+for ARCH in "${ARCHS[@]}"; do
+    case $ARCH in
+        amd64) RPM_ARCH=x86_64 ;;
+        arm64) RPM_ARCH=aarch64 ;;
+        *) RPM_ARCH=$ARCH ;;
+    esac
+
+    ROOT=$TOPDIR/stage_${ARCH}
+    stage "$VERSION" "$ARCH" "$ROOT"
+
+    rpmbuild -bb --target "$RPM_ARCH" \
+        --define "_topdir $TOPDIR/rpmbuild" \
+        --define "_rpmdir $PWD" \
+        --define "_build_name_fmt %%{NAME}-%%{VERSION}-%%{RELEASE}.%%{ARCH}.rpm" \
+        --define "gohome_version $VERSION" \
+        --define "gohome_stage $ROOT" \
+        "$SPEC"
+done
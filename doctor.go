@@ -0,0 +1,231 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+// doctorCheck is a single line of the compatibility report.
+type doctorCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// doctorReport collects the results of all compatibility checks and the visited pages.
+type doctorReport struct {
+	Checks  []doctorCheck
+	Pages   map[string]*matrixPage
+	Secrets []string
+}
+
+func (r *doctorReport) Check(name string, ok bool, detail string, args ...interface{}) bool {
+	r.Checks = append(r.Checks, doctorCheck{Name: name, OK: ok, Detail: fmt.Sprintf(detail, args...)})
+	return ok
+}
+
+func (r *doctorReport) AddPage(name string, page *matrixPage) {
+	if page == nil {
+		return
+	}
+	r.Pages[name] = page
+	if token, ok := page.UniqueToken(); ok {
+		r.AddSecrets(token)
+	}
+	if viewState, ok := page.ViewState(); ok {
+		r.AddSecrets(viewState)
+	}
+}
+
+func (r *doctorReport) AddSecrets(secrets ...string) {
+	r.Secrets = append(r.Secrets, secrets...)
+}
+
+func (r *doctorReport) Failed() int {
+	var count int
+	for _, c := range r.Checks {
+		if !c.OK {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *doctorReport) String() string {
+	var sb strings.Builder
+	for _, c := range r.Checks {
+		state := " OK "
+		if !c.OK {
+			state = "FAIL"
+		}
+		sb.WriteString(fmt.Sprintf("[%s] %-26s %s\n", state, c.Name, c.Detail))
+	}
+	return sb.String()
+}
+
+func cmdDoctor() error {
+	matrixConfig, err := getMatrixConfigWithPassword()
+	if err != nil {
+		return err
+	}
+
+	report := runDoctor(matrixConfig)
+	stdio.Print("%s", report.String())
+	stdio.Println("-----------------------------------------------------")
+	if failed := report.Failed(); failed > 0 {
+		stdio.Println("%d of %d checks failed", failed, len(report.Checks))
+	} else {
+		stdio.Println("all %d checks succeeded", len(report.Checks))
+	}
+
+	if len(cli.Doctor.Archive) > 0 {
+		if err := writeDoctorArchive(cli.Doctor.Archive, report); err != nil {
+			return fmt.Errorf("write debug archive: %s", err.Error())
+		}
+		stdio.Info("wrote redacted debug archive to %s", cli.Doctor.Archive)
+	}
+	return nil
+}
+
+// runDoctor logs in to Matrix and probes every page required by gohome.
+func runDoctor(config MatrixConfig) *doctorReport {
+	report := &doctorReport{Pages: make(map[string]*matrixPage)}
+	report.AddSecrets(config.User, config.Pass)
+	client := newMatrixClient(config)
+
+	if err := client.login(); err != nil {
+		report.Check("login", false, "%s", err.Error())
+		return report
+	}
+	report.Check("login", true, "as %q at %s", config.User, config.Host)
+	report.AddPage("login", client.lastPage)
+	report.AddSecrets(client.sessionID, client.rendermapToken)
+	report.Check("base url", true, "%s", matrixVersionURL)
+	report.Check("matrix version", len(client.ServerVersion()) > 0, "%s", valueOrUnknown(client.ServerVersion()))
+	locale, ok := client.lastPage.Locale()
+	report.Check("locale", ok, "%s", valueOrUnknown(locale))
+	report.Check("cookie "+matrixSessionCookieName, len(client.sessionID) > 0, "%s", foundOrMissing(len(client.sessionID) > 0))
+	report.Check("cookie "+matrixRendermapTokenCookieName, true, "%s", foundOrMissing(len(client.rendermapToken) > 0))
+
+	if err := client.visitSelfService(); err != nil {
+		report.Check("self-service menu", false, "%s", err.Error())
+		return report
+	}
+	report.AddPage("self-service", client.lastPage)
+	checkDoctorTokens(report, "self-service", client.lastPage)
+	report.Check("menu item bookings", client.HasMenuItem(matrixMenuItemBookings), "%s", valueOrUnknown(client.menuIDs[matrixMenuItemBookings]))
+	report.Check("menu item reconciliation", client.HasMenuItem(matrixMenuItemMonthData), "%s", valueOrUnknown(client.menuIDs[matrixMenuItemMonthData]))
+
+	bookingPage, err := client.visitBookings()
+	if report.Check("booking list", err == nil, "%s", errorOrPage(err, client.lastVisitedPage)) {
+		report.AddPage("bookings", bookingPage)
+		checkDoctorTokens(report, "booking list", bookingPage)
+		checkDoctorBookings(report, bookingPage)
+	}
+
+	monthPage, err := client.visitMonthlyReconciliation()
+	if report.Check("monthly reconciliation", err == nil, "%s", errorOrPage(err, client.lastVisitedPage)) {
+		report.AddPage("reconciliation", monthPage)
+		checkDoctorTokens(report, "monthly reconciliation", monthPage)
+		report.Check("selector flexi-time", monthPage.Count(matrixSelectorFlexiTime) > 0, "%s matched %d elements", matrixSelectorFlexiTime, monthPage.Count(matrixSelectorFlexiTime))
+		balance, err := monthPage.FlexiTimeBalance()
+		report.Check("flexi-time balance", err == nil, "%s", errorOrValue(err, formatSignedDurationMinutes(balance)))
+	}
+
+	client.Close()
+	return report
+}
+
+func checkDoctorTokens(report *doctorReport, prefix string, page *matrixPage) {
+	_, ok := page.UniqueToken()
+	report.Check(prefix+" token", ok, "%s %s", matrixSelectorUniqueToken, foundOrMissing(ok))
+	_, ok = page.ViewState()
+	report.Check(prefix+" view state", ok, "%s %s", matrixSelectorViewState, foundOrMissing(ok))
+}
+
+func checkDoctorBookings(report *doctorReport, page *matrixPage) {
+	count := page.Count(matrixSelectorBookingTable)
+	if !report.Check("selector booking table", count > 0, "%s matched %d elements", matrixSelectorBookingTable, count) {
+		return
+	}
+
+	table, err := findMatrixTable(page.doc.Find(matrixSelectorBookingTable))
+	if err != nil {
+		report.Check("booking table", false, "%s", err.Error())
+		return
+	}
+	report.Check("booking table", true, "%d rows, columns %q", len(table.Rows), table.Columns)
+	timeCol := table.Column(matrixBookingTimeColumns...)
+	report.Check("column time", timeCol >= 0, "%s", columnOrFallback(timeCol))
+	typeCol := table.Column(matrixBookingTypeColumns...)
+	report.Check("column booking type", typeCol >= 0, "%s", columnOrFallback(typeCol))
+
+	entries, err := page.BookingEntries(time.Now())
+	report.Check("bookings", err == nil, "%s", errorOrValue(err, fmt.Sprintf("%d entries parsed", len(entries))))
+}
+
+// writeDoctorArchive writes the report and all visited pages with secrets removed to a zip file.
+func writeDoctorArchive(file string, report *doctorReport) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("report.txt")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(redactSecrets(report.String(), report.Secrets...))); err != nil {
+		return err
+	}
+
+	for name, page := range report.Pages {
+		w, err := zw.Create(name + ".html")
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(redactMatrixPage(page.Body, report.Secrets...))); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func foundOrMissing(ok bool) string {
+	if ok {
+		return "found"
+	}
+	return "missing"
+}
+
+func valueOrUnknown(str string) string {
+	if len(str) == 0 {
+		return "unknown"
+	}
+	return str
+}
+
+func errorOrValue(err error, value string) string {
+	if err != nil {
+		return err.Error()
+	}
+	return value
+}
+
+func errorOrPage(err error, page string) string {
+	return errorOrValue(err, "visited "+page)
+}
+
+func columnOrFallback(col int) string {
+	if col < 0 {
+		return "not found by header, using column index"
+	}
+	return fmt.Sprintf("found at index %d", col)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"archive/zip"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDoctor(t *testing.T) {
+	fm := newFakeMatrix(t)
+
+	report := runDoctor(fm.Config())
+	assert.Equal(t, 0, report.Failed(), report.String())
+	assert.Contains(t, report.String(), "4.4.2")
+	assert.Contains(t, report.String(), "1 entries parsed")
+	assert.Contains(t, report.String(), "+01:30")
+}
+
+func TestRunDoctorLoginFailed(t *testing.T) {
+	fm := newFakeMatrix(t)
+	config := fm.Config()
+	config.Pass = "wrong"
+
+	report := runDoctor(config)
+	assert.Equal(t, 1, report.Failed())
+	assert.Len(t, report.Checks, 1)
+}
+
+func TestRunDoctorSelfServiceFailed(t *testing.T) {
+	fm := newFakeMatrix(t)
+	fm.MenuStatus = http.StatusInternalServerError
+
+	report := runDoctor(fm.Config())
+	assert.Equal(t, 1, report.Failed())
+	assert.Contains(t, report.String(), "self-service menu")
+	assert.NotContains(t, report.Pages, "self-service")
+}
+
+func TestWriteDoctorArchive(t *testing.T) {
+	fm := newFakeMatrix(t)
+	report := runDoctor(fm.Config())
+
+	file := filepath.Join(t.TempDir(), "debug.zip")
+	require.NoError(t, writeDoctorArchive(file, report))
+
+	zr, err := zip.OpenReader(file)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	names := make([]string, 0)
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		r.Close()
+		require.NoError(t, err)
+
+		for _, secret := range []string{fakeMatrixUser, fakeMatrixPass, fakeMatrixSessionID, "token-", "view-"} {
+			assert.False(t, strings.Contains(string(data), secret), "%s contains %q", f.Name, secret)
+		}
+	}
+	assert.Contains(t, names, "report.txt")
+	assert.Contains(t, names, "bookings.html")
+	assert.Contains(t, names, "reconciliation.html")
+}
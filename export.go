@@ -0,0 +1,323 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+var (
+	exportHeader  = []string{"date", "come", "leave", "trips", "work", "break", "accounted_work", "accounted_break", "flexi_delta", "note"}
+	exportWriters = map[string]func(io.Writer, []DaySummary) error{
+		"csv":  writeExportCSV,
+		"json": writeExportJSON,
+		"ics":  writeExportICS,
+		"xlsx": writeExportXLSX,
+	}
+)
+
+// exportDay is a day in JSON exports. Durations are given in minutes and omitted for incomplete days like in the CSV export.
+type exportDay struct {
+	Date                  string   `json:"date"`
+	Come                  string   `json:"come,omitempty"`
+	Leave                 string   `json:"leave,omitempty"`
+	Trips                 []string `json:"trips,omitempty"`
+	WorkMinutes           *int     `json:"work_minutes,omitempty"`
+	BreakMinutes          *int     `json:"break_minutes,omitempty"`
+	AccountedWorkMinutes  *int     `json:"accounted_work_minutes,omitempty"`
+	AccountedBreakMinutes *int     `json:"accounted_break_minutes,omitempty"`
+	FlexiMinutes          *int     `json:"flexi_minutes,omitempty"`
+	Incomplete            bool     `json:"incomplete,omitempty"`
+}
+
+// Come returns the first come time of the day.
+func (s DaySummary) Come() (time.Time, bool) {
+	if len(s.Blocks) == 0 {
+		return time.Time{}, false
+	}
+	return s.Blocks[0].Start, true
+}
+
+// Leave returns the last leave time of the day.
+func (s DaySummary) Leave() (time.Time, bool) {
+	if len(s.Blocks) == 0 || s.Incomplete || isToday(s.Date) && s.Entries[len(s.Entries)-1].Type != EntryTypeLeave {
+		return time.Time{}, false
+	}
+	return s.Blocks[len(s.Blocks)-1].End, true
+}
+
+// Trips returns the times of all business trips of the day.
+func (s DaySummary) Trips() []time.Time {
+	trips := make([]time.Time, 0)
+	for _, b := range s.Blocks {
+		trips = append(trips, b.Trips...)
+	}
+	return trips
+}
+
+// Note returns a hint for incomplete days.
+func (s DaySummary) Note() string {
+	if s.Incomplete {
+		return "missing leave booking"
+	}
+	return ""
+}
+
+// exportRecord returns a CSV record matching exportHeader.
+func exportRecord(s DaySummary) []string {
+	come, leave := "", ""
+	if t, ok := s.Come(); ok {
+		come = t.Format("15:04")
+	}
+	if t, ok := s.Leave(); ok {
+		leave = t.Format("15:04")
+	}
+	trips := make([]string, 0)
+	for _, t := range s.Trips() {
+		trips = append(trips, t.Format("15:04"))
+	}
+
+	record := []string{s.Date.Format(historyDateLayout), come, leave, strings.Join(trips, " "), "", "", "", "", "", s.Note()}
+	if !s.Incomplete {
+		record[4] = formatDurationMinutes(s.WorkTime)
+		record[5] = formatDurationMinutes(s.BreakTime)
+		record[6] = formatDurationMinutes(s.AccountedWorkTime)
+		record[7] = formatDurationMinutes(s.AccountedBreakTime)
+		record[8] = formatSignedDurationMinutes(s.FlexiTime)
+	}
+	return record
+}
+
+func writeExportCSV(w io.Writer, days []DaySummary) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportHeader); err != nil {
+		return err
+	}
+	for _, s := range days {
+		if err := cw.Write(exportRecord(s)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeExportJSON(w io.Writer, days []DaySummary) error {
+	out := make([]exportDay, 0, len(days))
+	for _, s := range days {
+		record := exportRecord(s)
+		day := exportDay{
+			Date:       record[0],
+			Come:       record[1],
+			Leave:      record[2],
+			Incomplete: s.Incomplete,
+		}
+		if !s.Incomplete {
+			day.WorkMinutes = exportMinutes(s.WorkTime)
+			day.BreakMinutes = exportMinutes(s.BreakTime)
+			day.AccountedWorkMinutes = exportMinutes(s.AccountedWorkTime)
+			day.AccountedBreakMinutes = exportMinutes(s.AccountedBreakTime)
+			day.FlexiMinutes = exportMinutes(s.FlexiTime)
+		}
+		if len(record[3]) > 0 {
+			day.Trips = strings.Split(record[3], " ")
+		}
+		out = append(out, day)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func exportMinutes(d time.Duration) *int {
+	minutes := int(d.Minutes())
+	return &minutes
+}
+
+// writeExportICS writes an iCalendar with one event per work block.
+func writeExportICS(w io.Writer, days []DaySummary) error {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//sbreitf1//gohome//EN", "CALSCALE:GREGORIAN"}
+	now := time.Now().UTC().Format("20060102T150405Z")
+	for _, s := range days {
+		for _, b := range s.Blocks {
+			if b.End.IsZero() {
+				continue
+			}
+			summary := "Work"
+			description := ""
+			if len(b.Trips) > 0 {
+				trips := make([]string, 0, len(b.Trips))
+				for _, t := range b.Trips {
+					trips = append(trips, t.Format("15:04"))
+				}
+				summary = "Work (business trip)"
+				description = "Business trips at " + strings.Join(trips, ", ")
+			}
+			lines = append(lines,
+				"BEGIN:VEVENT",
+				fmt.Sprintf("UID:%s@gohome", b.Start.UTC().Format("20060102T150405Z")),
+				"DTSTAMP:"+now,
+				"DTSTART:"+b.Start.UTC().Format("20060102T150405Z"),
+				"DTEND:"+b.End.UTC().Format("20060102T150405Z"),
+				"SUMMARY:"+summary,
+			)
+			if len(description) > 0 {
+				lines = append(lines, "DESCRIPTION:"+escapeICSText(description))
+			}
+			lines = append(lines, "TRANSP:TRANSPARENT", "END:VEVENT")
+		}
+	}
+	lines = append(lines, "END:VCALENDAR")
+	_, err := io.WriteString(w, strings.Join(lines, "\r\n")+"\r\n")
+	return err
+}
+
+func escapeICSText(str string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(str)
+}
+
+// exportDays returns the summaries of all days with entries in the given range. Missing days are fetched from Matrix with fetch.
+func exportDays(from, to time.Time, targetTime time.Duration, fetch bool) ([]DaySummary, error) {
+	h, err := ReadHistory()
+	if err != nil {
+		return nil, fmt.Errorf("read history: %s", err.Error())
+	}
+
+	if fetch {
+		if err := fetchHistory(h, from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	days := make([]DaySummary, 0)
+	for _, date := range h.Dates(from, to) {
+		day, _ := h.Get(date)
+		s, err := SummarizeDay(date, day.Entries, targetTime)
+		if err != nil {
+			stdio.Warn("skip %s: %s", date.Format(historyDateLayout), err.Error())
+			continue
+		}
+		days = append(days, s)
+	}
+	return days, nil
+}
+
+// fetchHistory fetches all days in the given range that are missing in the history or have been fetched on the same day.
+func fetchHistory(h *History, from, to time.Time) error {
+	missing := missingHistoryDates(h, from, to)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	matrixConfig, err := getMatrixConfigWithPassword()
+	if err != nil {
+		return err
+	}
+	client, err := NewMatrixClient(matrixConfig)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return fetchHistoryDates(client, h, missing)
+}
+
+// missingHistoryDates returns all dates in the given range until today that need to be fetched. Days fetched on the same day might have been incomplete.
+func missingHistoryDates(h *History, from, to time.Time) []time.Time {
+	missing := make([]time.Time, 0)
+	for date := truncateDay(from); !date.After(to) && !date.After(time.Now()); date = date.AddDate(0, 0, 1) {
+		if day, ok := h.Get(date); ok && !isToday(date) && truncateDay(day.Updated).After(date) {
+			continue
+		}
+		missing = append(missing, date)
+	}
+	return missing
+}
+
+// fetchHistoryDates fetches the entries of the given dates and writes the history. Already fetched days are kept on error.
+func fetchHistoryDates(client *MatrixClient, h *History, dates []time.Time) error {
+	for _, date := range dates {
+		matrixLog.Debug("fetch entries", "date", date.Format(historyDateLayout))
+		entries, err := client.GetEntriesForDay(date)
+		if err != nil {
+			if writeErr := h.Write(); writeErr != nil {
+				stdio.Warn("write history failed: %s", writeErr.Error())
+			}
+			return fmt.Errorf("fetch entries of %s: %s", date.Format(historyDateLayout), err.Error())
+		}
+		h.Put(date, entries, nil)
+	}
+	return h.Write()
+}
+
+// parseDateRange parses dates in format "2006-01-02". The range defaults to the current month until today.
+func parseDateRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to := truncateDay(now)
+	if len(fromStr) > 0 {
+		var err error
+		from, err = time.ParseInLocation(historyDateLayout, fromStr, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse from date: %s", err.Error())
+		}
+	}
+	if len(toStr) > 0 {
+		var err error
+		to, err = time.ParseInLocation(historyDateLayout, toStr, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse to date: %s", err.Error())
+		}
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to date is before from date")
+	}
+	return from, to, nil
+}
+
+func cmdExport() error {
+	write, ok := exportWriters[cli.Export.Format]
+	if !ok {
+		return fmt.Errorf("unknown export format %q", cli.Export.Format)
+	}
+	from, to, err := parseDateRange(cli.Export.From, cli.Export.To)
+	if err != nil {
+		return err
+	}
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		stdio.Warn("read user config failed: %s", err.Error())
+	}
+	targetTime, err := parseTargetTime(cli.Export.TargetTime, usrConf)
+	if err != nil {
+		return err
+	}
+
+	days, err := exportDays(from, to, targetTime, cli.Export.Fetch)
+	if err != nil {
+		return err
+	}
+
+	if len(cli.Export.Output) == 0 || cli.Export.Output == "-" {
+		return write(os.Stdout, days)
+	}
+	f, err := os.Create(cli.Export.Output)
+	if err != nil {
+		return err
+	}
+	if err := write(f, days); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	stdio.Info("exported %d days to %s", len(days), cli.Export.Output)
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExportDays(t *testing.T) []DaySummary {
+	day := time.Date(2026, time.October, 13, 0, 0, 0, 0, time.Local)
+	complete, err := SummarizeDay(day, []Entry{
+		{Type: EntryTypeCome, Time: day.Add(8 * time.Hour)},
+		{Type: EntryTypeTrip, Time: day.Add(10 * time.Hour)},
+		{Type: EntryTypeCome, Time: day.Add(11 * time.Hour)},
+		{Type: EntryTypeLeave, Time: day.Add(12 * time.Hour)},
+		{Type: EntryTypeCome, Time: day.Add(12*time.Hour + 30*time.Minute)},
+		{Type: EntryTypeLeave, Time: day.Add(17 * time.Hour)},
+	}, 8*time.Hour)
+	require.NoError(t, err)
+
+	day = day.AddDate(0, 0, 1)
+	incomplete, err := SummarizeDay(day, []Entry{{Type: EntryTypeCome, Time: day.Add(9 * time.Hour)}}, 8*time.Hour)
+	require.NoError(t, err)
+	return []DaySummary{complete, incomplete}
+}
+
+func TestSummarizeDay(t *testing.T) {
+	days := testExportDays(t)
+	require.Len(t, days[0].Blocks, 2)
+	assert.Len(t, days[0].Blocks[0].Trips, 1)
+	assert.Equal(t, 8*time.Hour+30*time.Minute, days[0].WorkTime)
+	assert.Equal(t, 30*time.Minute, days[0].FlexiTime)
+	assert.False(t, days[0].Incomplete)
+
+	assert.True(t, days[1].Incomplete)
+	_, ok := days[1].Leave()
+	assert.False(t, ok)
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeExportCSV(&buf, testExportDays(t)))
+	assert.Equal(t, `date,come,leave,trips,work,break,accounted_work,accounted_break,flexi_delta,note
+2026-10-13,08:00,17:00,10:00,08:30,00:30,08:30,00:30,+00:30,
+2026-10-14,09:00,,,,,,,,missing leave booking
+`, buf.String())
+}
+
+func TestExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeExportJSON(&buf, testExportDays(t)))
+	var days []exportDay
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &days))
+	require.Len(t, days, 2)
+	assert.Equal(t, []string{"10:00"}, days[0].Trips)
+	require.NotNil(t, days[0].AccountedWorkMinutes)
+	assert.Equal(t, 510, *days[0].AccountedWorkMinutes)
+	require.NotNil(t, days[0].FlexiMinutes)
+	assert.Equal(t, 30, *days[0].FlexiMinutes)
+	assert.True(t, days[1].Incomplete)
+	assert.Nil(t, days[1].WorkMinutes)
+	assert.Nil(t, days[1].AccountedWorkMinutes)
+	assert.Nil(t, days[1].FlexiMinutes)
+}
+
+func TestExportICS(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeExportICS(&buf, testExportDays(t)))
+	ics := buf.String()
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(ics, "BEGIN:VEVENT"), "one event per complete work block")
+	assert.Contains(t, ics, "SUMMARY:Work (business trip)\r\nDESCRIPTION:Business trips at 10:00\r\n")
+	assert.Contains(t, ics, "DTSTART:"+time.Date(2026, time.October, 13, 12, 30, 0, 0, time.Local).UTC().Format("20060102T150405Z"))
+}
+
+func TestExportXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeExportXLSX(&buf, testExportDays(t)))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			sheet = string(data)
+		}
+	}
+	assert.Contains(t, sheet, `<c r="A1" t="inlineStr"><is><t>date</t></is></c>`)
+	// 2026-10-13 and 08:00 as spreadsheet values
+	assert.Contains(t, sheet, `<c r="A2" s="1"><v>46308</v></c><c r="B2" s="3"><v>0.3333333333333333</v></c>`)
+	assert.Contains(t, sheet, `<c r="I2" t="inlineStr"><is><t>+00:30</t></is></c>`)
+	assert.Equal(t, "AB", xlsxColumn(27))
+}
+
+func TestExportFetch(t *testing.T) {
+	useTempConfigDir(t)
+	fm := newFakeMatrix(t)
+	fm.History["13.10.2026"] = fakeMatrixBookingRows("Arrive", "08:00", "Leave", "16:30")
+	client, err := NewMatrixClient(fm.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	day := time.Date(2026, time.October, 13, 0, 0, 0, 0, time.Local)
+	entries, err := client.GetEntriesForDay(day)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, day.Add(16*time.Hour+30*time.Minute), entries[1].Time)
+
+	entries, err = client.GetEntriesForDay(day.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestHistory(t *testing.T) {
+	useTempConfigDir(t)
+	now := time.Now()
+	require.NoError(t, WriteCache([]Entry{{Type: EntryTypeCome, Time: now}}, time.Hour))
+
+	h, err := ReadHistory()
+	require.NoError(t, err)
+	day, ok := h.Get(now)
+	require.True(t, ok)
+	assert.Len(t, day.Entries, 1)
+	require.NotNil(t, day.FlexiTimeBalance)
+	assert.Equal(t, time.Hour, *day.FlexiTimeBalance)
+
+	h.Put(now, nil, nil)
+	day, _ = h.Get(now)
+	assert.Equal(t, time.Hour, *day.FlexiTimeBalance, "balance is kept")
+	assert.Empty(t, h.Dates(now.AddDate(0, 0, -1), now))
+}
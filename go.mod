@@ -3,18 +3,21 @@ module github.com/sbreitf1/gohome
 go 1.25.0
 
 require (
+	github.com/PuerkitoBio/goquery v1.10.3
 	github.com/adrg/xdg v0.5.3
 	github.com/alecthomas/kong v1.16.0
-	github.com/beevik/etree v1.7.0
 	github.com/danielb42/goat v1.0.1
+	github.com/godbus/dbus/v5 v5.2.2
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/crypto v0.54.0
 	golang.org/x/term v0.45.0
 )
 
 require (
+	github.com/andybalholm/cascadia v1.3.3 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.56.0 // indirect
 	golang.org/x/sys v0.47.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	historyDateLayout = "2006-01-02"
+)
+
+// HistoryDay contains the entries of a day as last fetched from Matrix.
+type HistoryDay struct {
+	Entries []Entry
+	// FlexiTimeBalance is the balance of the previous day. It is only known for days fetched on the same day.
+	FlexiTimeBalance *time.Duration `json:",omitempty"`
+	Updated          time.Time
+}
+
+// History contains the entries of all days fetched before, indexed by date in format "2006-01-02".
+type History struct {
+	Days map[string]HistoryDay
+}
+
+// ReadHistory returns the history from the config dir or an empty history.
+func ReadHistory() (*History, error) {
+	h := &History{Days: make(map[string]HistoryDay)}
+	data, err := os.ReadFile(historyFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+	if h.Days == nil {
+		h.Days = make(map[string]HistoryDay)
+	}
+	return h, nil
+}
+
+// Write stores the history in the config dir.
+func (h *History) Write() error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(getConfigDir(), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(historyFile(), data, os.ModePerm)
+}
+
+// Put replaces the entries of a day. A nil flexiTimeBalance keeps the known balance.
+func (h *History) Put(day time.Time, entries []Entry, flexiTimeBalance *time.Duration) {
+	key := day.Format(historyDateLayout)
+	if flexiTimeBalance == nil {
+		flexiTimeBalance = h.Days[key].FlexiTimeBalance
+	}
+	h.Days[key] = HistoryDay{Entries: entries, FlexiTimeBalance: flexiTimeBalance, Updated: time.Now()}
+}
+
+// Get returns the entries of a day.
+func (h *History) Get(day time.Time) (HistoryDay, bool) {
+	d, ok := h.Days[day.Format(historyDateLayout)]
+	return d, ok
+}
+
+// Dates returns all dates between from and to (inclusive) with entries in ascending order.
+func (h *History) Dates(from, to time.Time) []time.Time {
+	dates := make([]time.Time, 0)
+	for key, d := range h.Days {
+		date, err := time.ParseInLocation(historyDateLayout, key, time.Local)
+		if err != nil || len(d.Entries) == 0 || date.Before(truncateDay(from)) || date.After(to) {
+			continue
+		}
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates
+}
+
+// RecordHistory stores today's entries and flexi-time balance in the history.
+func RecordHistory(entries []Entry, flexiTimeBalance time.Duration) error {
+	h, err := ReadHistory()
+	if err != nil {
+		return err
+	}
+	h.Put(time.Now(), entries, &flexiTimeBalance)
+	return h.Write()
+}
+
+func historyFile() string {
+	return filepath.Join(getConfigDir(), "history.json")
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,100 @@
+package stdio
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ColorLevel is the color support of a terminal.
+type ColorLevel int
+
+const (
+	ColorNone ColorLevel = iota
+	// ColorBasic supports the 16 ANSI colors.
+	ColorBasic
+	Color256
+	ColorTrue
+)
+
+var (
+	// ColorMode is auto, always or never.
+	ColorMode = "auto"
+)
+
+// ColorSupport returns the color level of stdout with respect to ColorMode and the environment.
+func ColorSupport() ColorLevel {
+	return DetectColorLevel(ColorMode, os.Getenv, term.IsTerminal(int(os.Stdout.Fd())))
+}
+
+// DetectColorLevel returns the color level for a mode, environment variables and whether the output is a terminal. NO_COLOR, CLICOLOR=0 and TERM=dumb disable colors, FORCE_COLOR and CLICOLOR_FORCE enable them for non-terminals.
+func DetectColorLevel(mode string, getenv func(string) string, isTerminal bool) ColorLevel {
+	switch mode {
+	case "never":
+		return ColorNone
+	case "always":
+		return maxColorLevel(terminalColorLevel(getenv), ColorBasic)
+	}
+
+	if len(getenv("NO_COLOR")) > 0 {
+		return ColorNone
+	}
+	if force, ok := forcedColorLevel(getenv); ok {
+		if force == ColorNone {
+			return ColorNone
+		}
+		return maxColorLevel(terminalColorLevel(getenv), force)
+	}
+	if v := getenv("CLICOLOR_FORCE"); len(v) > 0 && v != "0" {
+		return maxColorLevel(terminalColorLevel(getenv), ColorBasic)
+	}
+	if getenv("CLICOLOR") == "0" || !isTerminal {
+		return ColorNone
+	}
+	return terminalColorLevel(getenv)
+}
+
+// forcedColorLevel evaluates FORCE_COLOR, where 0 or false disables colors and 1 to 3 request basic, 256 or true colors.
+func forcedColorLevel(getenv func(string) string) (ColorLevel, bool) {
+	v := getenv("FORCE_COLOR")
+	if len(v) == 0 {
+		return ColorNone, false
+	}
+	switch strings.ToLower(v) {
+	case "0", "false":
+		return ColorNone, true
+	case "2":
+		return Color256, true
+	case "3":
+		return ColorTrue, true
+	default:
+		return ColorBasic, true
+	}
+}
+
+// terminalColorLevel derives the color level from TERM and COLORTERM.
+func terminalColorLevel(getenv func(string) string) ColorLevel {
+	termName := getenv("TERM")
+	if termName == "dumb" {
+		return ColorNone
+	}
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorTrue
+	}
+	if strings.Contains(termName, "256color") {
+		return Color256
+	}
+	if strings.HasSuffix(termName, "-direct") {
+		return ColorTrue
+	}
+	return ColorBasic
+}
+
+func maxColorLevel(a, b ColorLevel) ColorLevel {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,36 @@
+package stdio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectColorLevel(t *testing.T) {
+	env := func(vars ...string) func(string) string {
+		return func(key string) string {
+			for i := 0; i+1 < len(vars); i += 2 {
+				if vars[i] == key {
+					return vars[i+1]
+				}
+			}
+			return ""
+		}
+	}
+
+	assert.Equal(t, ColorBasic, DetectColorLevel("auto", env("TERM", "xterm"), true))
+	assert.Equal(t, Color256, DetectColorLevel("auto", env("TERM", "xterm-256color"), true))
+	assert.Equal(t, ColorTrue, DetectColorLevel("auto", env("TERM", "xterm-256color", "COLORTERM", "truecolor"), true))
+	assert.Equal(t, ColorNone, DetectColorLevel("auto", env("TERM", "xterm-256color"), false))
+	assert.Equal(t, ColorNone, DetectColorLevel("auto", env("TERM", "dumb"), true))
+	assert.Equal(t, ColorNone, DetectColorLevel("auto", env("TERM", "xterm", "NO_COLOR", "1"), true))
+	assert.Equal(t, ColorNone, DetectColorLevel("auto", env("TERM", "xterm", "CLICOLOR", "0"), true))
+	assert.Equal(t, ColorNone, DetectColorLevel("auto", env("TERM", "xterm", "FORCE_COLOR", "0"), true))
+	assert.Equal(t, ColorBasic, DetectColorLevel("auto", env("FORCE_COLOR", "1"), false))
+	assert.Equal(t, ColorTrue, DetectColorLevel("auto", env("FORCE_COLOR", "3"), false))
+	assert.Equal(t, ColorBasic, DetectColorLevel("auto", env("CLICOLOR_FORCE", "1"), false))
+
+	assert.Equal(t, ColorBasic, DetectColorLevel("always", env("NO_COLOR", "1", "TERM", "dumb"), false))
+	assert.Equal(t, Color256, DetectColorLevel("always", env("TERM", "screen-256color"), false))
+	assert.Equal(t, ColorNone, DetectColorLevel("never", env("FORCE_COLOR", "3"), true))
+}
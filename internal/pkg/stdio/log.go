@@ -0,0 +1,275 @@
+package stdio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LogConfig defines where and how diagnostics are written.
+type LogConfig struct {
+	// File is the log file, stderr is used if empty.
+	File string
+	// Format is text or json.
+	Format string
+	// Level is the minimum level of all subsystems without own level in Levels.
+	Level  slog.Level
+	Levels map[string]slog.Level
+}
+
+type logState struct {
+	handler slog.Handler
+	level   slog.Level
+	levels  map[string]slog.Level
+}
+
+var (
+	currentLog atomic.Pointer[logState]
+	// defaultLog is used by Debug, Info, Warn and Error.
+	defaultLog = Logger("")
+)
+
+func init() {
+	currentLog.Store(&logState{handler: newTextHandler(os.Stderr, false), level: slog.LevelInfo})
+}
+
+// ConfigureLogging replaces the log output and levels. The returned function closes the log file and logs to stderr again.
+func ConfigureLogging(conf LogConfig) (func() error, error) {
+	if conf.Format != "" && conf.Format != "text" && conf.Format != "json" {
+		return nil, fmt.Errorf("unknown log format %q, expected text or json", conf.Format)
+	}
+
+	var w io.Writer = os.Stderr
+	closeFn := func() error { return nil }
+	if len(conf.File) > 0 {
+		f, err := os.OpenFile(conf.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("open log file: %s", err.Error())
+		}
+		w = f
+		closeFn = func() error {
+			state := *currentLog.Load()
+			state.handler = newTextHandler(os.Stderr, false)
+			currentLog.Store(&state)
+			return f.Close()
+		}
+	}
+
+	// log files need timestamps, the terminal does not
+	var handler slog.Handler = newTextHandler(w, len(conf.File) > 0)
+	if conf.Format == "json" {
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.LevelKey {
+				return slog.String(slog.LevelKey, LevelName(a.Value.Any().(slog.Level)))
+			}
+			return a
+		}})
+	}
+
+	currentLog.Store(&logState{handler: handler, level: conf.Level, levels: conf.Levels})
+	return closeFn, nil
+}
+
+// ParseLogLevels parses levels like "info" or "warn,matrix=debug,cache=info" where entries without subsystem set the default level.
+func ParseLogLevels(str string, defaultLevel slog.Level) (slog.Level, map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for _, part := range strings.Split(str, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		subsystem, levelStr, hasSubsystem := strings.Cut(part, "=")
+		if !hasSubsystem {
+			levelStr = subsystem
+		}
+		var level slog.Level
+		if strings.EqualFold(levelStr, "err") {
+			level = slog.LevelError
+		} else if err := level.UnmarshalText([]byte(levelStr)); err != nil {
+			return 0, nil, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", levelStr)
+		}
+		if hasSubsystem {
+			levels[strings.TrimSpace(subsystem)] = level
+		} else {
+			defaultLevel = level
+		}
+	}
+	return defaultLevel, levels, nil
+}
+
+// Logger returns a structured logger for a subsystem like matrix, cache or worktime. Its level can be configured separately.
+func Logger(subsystem string) *slog.Logger {
+	return slog.New(&subsystemHandler{subsystem: subsystem})
+}
+
+// subsystemHandler forwards records to the currently configured handler if the level of the subsystem is enabled.
+type subsystemHandler struct {
+	subsystem string
+	attrs     []slog.Attr
+	groups    []string
+}
+
+func (h *subsystemHandler) Enabled(_ context.Context, level slog.Level) bool {
+	state := currentLog.Load()
+	if l, ok := state.levels[h.subsystem]; ok {
+		return level >= l
+	}
+	return level >= state.level
+}
+
+func (h *subsystemHandler) Handle(ctx context.Context, r slog.Record) error {
+	handler := currentLog.Load().handler
+	if len(h.subsystem) > 0 {
+		handler = handler.WithAttrs([]slog.Attr{slog.String("subsystem", h.subsystem)})
+	}
+	for _, g := range h.groups {
+		handler = handler.WithGroup(g)
+	}
+	if len(h.attrs) > 0 {
+		handler = handler.WithAttrs(h.attrs)
+	}
+	return handler.Handle(ctx, r)
+}
+
+func (h *subsystemHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(h.groups) > 0 {
+		// attributes belong to the innermost group and cannot be reordered
+		return &groupedHandler{parent: h, attrs: attrs}
+	}
+	return &subsystemHandler{subsystem: h.subsystem, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...), groups: h.groups}
+}
+
+func (h *subsystemHandler) WithGroup(name string) slog.Handler {
+	return &groupedHandler{parent: h, group: name}
+}
+
+// groupedHandler applies a group or attributes after those of its parent.
+type groupedHandler struct {
+	parent slog.Handler
+	group  string
+	attrs  []slog.Attr
+}
+
+func (h *groupedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.parent.Enabled(ctx, level)
+}
+
+func (h *groupedHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]any, 0, r.NumAttrs()+len(h.attrs))
+	for _, a := range h.attrs {
+		attrs = append(attrs, a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	if len(h.group) > 0 {
+		if len(attrs) > 0 {
+			nr.AddAttrs(slog.Group(h.group, attrs...))
+		}
+	} else {
+		nr.Add(attrs...)
+	}
+	return h.parent.Handle(ctx, nr)
+}
+
+func (h *groupedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &groupedHandler{parent: h, attrs: attrs}
+}
+
+func (h *groupedHandler) WithGroup(name string) slog.Handler {
+	return &groupedHandler{parent: h, group: name}
+}
+
+// textHandler writes records like "[WARN] matrix: message key=value".
+type textHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	time  bool
+	attrs []slog.Attr
+}
+
+func newTextHandler(w io.Writer, withTime bool) *textHandler {
+	return &textHandler{w: w, mu: &sync.Mutex{}, time: withTime}
+}
+
+func (h *textHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+	if h.time {
+		sb.WriteString(r.Time.Format(time.RFC3339) + " ")
+	}
+	sb.WriteString("[" + LevelName(r.Level) + "] ")
+
+	attrs := make([]slog.Attr, 0, len(h.attrs)+r.NumAttrs())
+	attrs = append(attrs, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for _, a := range attrs {
+		if a.Key == "subsystem" {
+			sb.WriteString(a.Value.String() + ": ")
+		}
+	}
+	sb.WriteString(r.Message)
+	for _, a := range attrs {
+		if a.Key != "subsystem" {
+			writeTextAttr(&sb, "", a)
+		}
+	}
+	sb.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, sb.String())
+	return err
+}
+
+func writeTextAttr(sb *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			writeTextAttr(sb, prefix+a.Key+".", ga)
+		}
+		return
+	}
+	value := a.Value.String()
+	if strings.ContainsAny(value, " \"=") || len(value) == 0 {
+		value = fmt.Sprintf("%q", value)
+	}
+	sb.WriteString(" " + prefix + a.Key + "=" + value)
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &textHandler{w: h.w, mu: h.mu, time: h.time, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	// groups are resolved by groupedHandler before records reach this handler
+	return h
+}
+
+// LevelName returns the names used in log prefixes like "[WARN]". They are used by gohome since before structured logging.
+func LevelName(level slog.Level) string {
+	switch {
+	case level < slog.LevelInfo:
+		return "DEBUG"
+	case level < slog.LevelWarn:
+		return "INFO"
+	case level < slog.LevelError:
+		return "WARN"
+	default:
+		return "ERR"
+	}
+}
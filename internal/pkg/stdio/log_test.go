@@ -0,0 +1,90 @@
+package stdio
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogLevels(t *testing.T) {
+	level, levels, err := ParseLogLevels("", slog.LevelInfo)
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelInfo, level)
+	assert.Empty(t, levels)
+
+	level, levels, err = ParseLogLevels("warn, matrix=debug,cache=ERR", slog.LevelInfo)
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+	assert.Equal(t, map[string]slog.Level{"matrix": slog.LevelDebug, "cache": slog.LevelError}, levels)
+
+	_, _, err = ParseLogLevels("matrix=verbose", slog.LevelInfo)
+	assert.EqualError(t, err, `invalid log level "verbose", expected debug, info, warn or error`)
+}
+
+func configureTestLog(t *testing.T, conf LogConfig) func() string {
+	conf.File = filepath.Join(t.TempDir(), "gohome.log")
+	closeLog, err := ConfigureLogging(conf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		closeLog()
+		ConfigureLogging(LogConfig{Level: slog.LevelInfo})
+	})
+	return func() string {
+		data, err := os.ReadFile(conf.File)
+		require.NoError(t, err)
+		return string(data)
+	}
+}
+
+func TestLoggerSubsystemLevels(t *testing.T) {
+	read := configureTestLog(t, LogConfig{Level: slog.LevelWarn, Levels: map[string]slog.Level{"matrix": slog.LevelDebug}})
+
+	Logger("matrix").Debug("visit page", "page", "/mainMenu.jsf", "title", "Main Menu")
+	Logger("cache").Info("cache is valid")
+	Logger("cache").With("file", "cache.json").Warn("cache corrupt")
+	Debug("hidden %d", 1)
+	Error("failed: %s", "timeout")
+
+	lines := strings.Split(strings.TrimSpace(read()), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasSuffix(lines[0], ` [DEBUG] matrix: visit page page=/mainMenu.jsf title="Main Menu"`), lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], ` [WARN] cache: cache corrupt file=cache.json`), lines[1])
+	assert.True(t, strings.HasSuffix(lines[2], ` [ERR] failed: timeout`), lines[2])
+}
+
+func TestLoggerJSON(t *testing.T) {
+	read := configureTestLog(t, LogConfig{Format: "json", Level: slog.LevelDebug})
+
+	Logger("worktime").WithGroup("target").Info("computed", "duration", "8h0m0s")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(read()), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "worktime", record["subsystem"])
+	assert.Equal(t, "computed", record["msg"])
+	assert.Equal(t, map[string]interface{}{"duration": "8h0m0s"}, record["target"])
+}
+
+func TestConfigureLoggingInvalidFormat(t *testing.T) {
+	_, err := ConfigureLogging(LogConfig{Format: "xml"})
+	assert.EqualError(t, err, `unknown log format "xml", expected text or json`)
+}
+
+func TestCloseLogFileRestoresStderr(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gohome.log")
+	closeLog, err := ConfigureLogging(LogConfig{File: file, Level: slog.LevelWarn})
+	require.NoError(t, err)
+	require.NoError(t, closeLog())
+	t.Cleanup(func() { ConfigureLogging(LogConfig{Level: slog.LevelInfo}) })
+
+	handler, ok := currentLog.Load().handler.(*textHandler)
+	require.True(t, ok)
+	assert.Equal(t, os.Stderr, handler.w)
+	assert.Equal(t, slog.LevelWarn, currentLog.Load().level)
+}
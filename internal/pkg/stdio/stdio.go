@@ -2,33 +2,52 @@ package stdio
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"syscall"
+	"unicode/utf8"
 
 	"golang.org/x/term"
 )
 
-var (
-	Verbose bool
+// Key is a key pressed in raw mode.
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeyEscape
 )
 
+// Debug logs a formatted message of the default subsystem. Like Info, Warn and Error it is written to the log output which is stderr by default.
 func Debug(msg string, args ...interface{}) {
-	if Verbose {
-		fmt.Println("[DEBUG]", fmt.Sprintf(msg, args...))
-	}
+	Log(slog.LevelDebug, msg, args...)
 }
 
 func Info(msg string, args ...interface{}) {
-	fmt.Println("[INFO]", fmt.Sprintf(msg, args...))
+	Log(slog.LevelInfo, msg, args...)
 }
 
 func Warn(msg string, args ...interface{}) {
-	fmt.Println("[WARN]", fmt.Sprintf(msg, args...))
+	Log(slog.LevelWarn, msg, args...)
 }
 
 func Error(msg string, args ...interface{}) {
-	fmt.Println("[ERR]", fmt.Sprintf(msg, args...))
+	Log(slog.LevelError, msg, args...)
+}
+
+// Log logs a formatted message of the default subsystem with the given level.
+func Log(level slog.Level, msg string, args ...interface{}) {
+	ctx := context.Background()
+	if defaultLog.Enabled(ctx, level) {
+		defaultLog.Log(ctx, level, fmt.Sprintf(msg, args...))
+	}
 }
 
 func Print(msg string, args ...interface{}) {
@@ -62,7 +81,49 @@ func ReadPassword() (string, error) {
 	return string(pass), nil
 }
 
+// MakeRaw puts the terminal into raw mode and returns a function to restore the previous mode.
+func MakeRaw() (func(), error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("stdin is not a terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() { term.Restore(fd, state) }, nil
+}
+
+// ReadKey reads a single key press in raw mode. Ctrl+C is returned as KeyEscape.
+func ReadKey() (Key, rune, error) {
+	buf := make([]byte, 8)
+	n, err := os.Stdin.Read(buf)
+	if err != nil {
+		return KeyEscape, 0, err
+	}
+	if n >= 3 && buf[0] == 27 && (buf[1] == '[' || buf[1] == 'O') {
+		switch buf[2] {
+		case 'A':
+			return KeyUp, 0, nil
+		case 'B':
+			return KeyDown, 0, nil
+		case 'C':
+			return KeyRight, 0, nil
+		case 'D':
+			return KeyLeft, 0, nil
+		}
+	}
+	switch buf[0] {
+	case 27, 3:
+		return KeyEscape, 0, nil
+	case '\r', '\n':
+		return KeyEnter, 0, nil
+	}
+	r, _ := utf8.DecodeRune(buf[:n])
+	return KeyRune, r, nil
+}
+
+// SupportsColors returns true if colored output is enabled for stdout.
 func SupportsColors() bool {
-	//TODO implement SupportsColors
-	return true
+	return ColorSupport() > ColorNone
 }
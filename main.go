@@ -2,54 +2,218 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"time"
 
 	"github.com/sbreitf1/gohome/internal/pkg/stdio"
 
 	"github.com/alecthomas/kong"
-	"github.com/danielb42/goat"
 )
 
 var (
 	cli struct {
-		Verbose bool `name:"verbose" short:"v" help:"more verbose printing"`
-		Debug   bool `name:"debug" help:"maximum debug output including scraped files"`
+		Verbose        bool             `name:"verbose" short:"v" help:"more verbose printing"`
+		Debug          bool             `name:"debug" help:"maximum debug output including redacted scraped files"`
+		DumpDir        string           `name:"dump-dir" default:"." completion:"dir" help:"directory for scraped files and request manifest in debug mode"`
+		Color          string           `name:"color" default:"auto" enum:"auto,always,never" help:"colored output: auto, always or never"`
+		LogLevel       string           `name:"log-level" placeholder:"LEVELS" help:"log level like 'warn' and levels of the subsystems matrix, cache and worktime like 'info,matrix=debug'"`
+		LogFile        string           `name:"log-file" completion:"file" help:"write log messages to a file instead of stderr"`
+		LogFormat      string           `name:"log-format" default:"text" enum:"text,json" help:"log format: text or json"`
+		NonInteractive bool             `name:"non-interactive" help:"fail instead of prompting for Matrix configuration or password"`
+		ShowVersion    kong.VersionFlag `name:"version" help:"print version and exit"`
 
 		Show struct {
-			TargetTime       string `name:"target-time" short:"t" default:"08:00" help:"assume target time in format '15:04'"`
-			LeaveTime        string `name:"leave-time" short:"l" default:"" help:"simulate a given leave time in format '15:04'"`
-			BreakTime        string `name:"break-time" short:"b" default:"" help:"simulate a given break time in format '15:04'"`
+			TargetTime       string `name:"target-time" short:"t" default:"08:00" completion:"time" help:"assume target time in format '15:04'"`
+			LeaveTime        string `name:"leave-time" short:"l" default:"" completion:"time" help:"simulate a given leave time in format '15:04'"`
+			BreakTime        string `name:"break-time" short:"b" default:"" completion:"time" help:"simulate a given break time in format '15:04'"`
 			ForceReload      bool   `name:"force-reload" short:"f" help:"ignore local cache and force refresh of entries"`
 			CacheTimeSeconds int    `name:"cache-time" default:"600" help:"max cache age in seconds"`
-			SetReminder      bool   `name:"set-reminder" short:"r" help:"sets a reminder via linux at command"`
+			SetReminder      bool   `name:"set-reminder" short:"r" help:"sets reminders for the configured milestones"`
+			Template         string `name:"template" completion:"template" help:"built-in template default, compact or minimal, or path to a template file"`
+			TomorrowTarget   string `name:"tomorrow-target" completion:"time" help:"plan tomorrow's earliest start and leave time for a target time in format '15:04'"`
 
 			SaveConfig bool `name:"save-config" help:"DEPRECATED - write changes from command line parameters to user config"`
 		} `cmd:"show" default:"withargs" help:"Show today's stats"`
 
 		DumpColors struct {
 		} `cmd:"dump-colors" help:"Populates colors.json in the application config directory"`
+
+		Clock struct {
+			Action string `arg:"" enum:"in,out,trip" help:"booking to submit: in, out or trip"`
+			DryRun bool   `name:"dry-run" short:"n" help:"prepare the booking without submitting it"`
+			Force  bool   `name:"force" help:"book even if the booking does not match the current state"`
+		} `cmd:"clock" help:"Submit a booking to the Matrix web terminal"`
+
+		Correct struct {
+			Date   string `name:"date" short:"d" help:"day to correct in format '2006-01-02', defaults to today"`
+			Come   string `name:"come" completion:"time" help:"missing come booking in format '15:04'"`
+			Leave  string `name:"leave" completion:"time" help:"missing leave booking in format '15:04'"`
+			Reason string `name:"reason" help:"reason for the correction"`
+			DryRun bool   `name:"dry-run" short:"n" help:"fill the correction form without submitting it"`
+			List   bool   `name:"list" short:"l" help:"list pending and approved correction requests instead"`
+		} `cmd:"correct" help:"Request a correction for missing bookings"`
+
+		Balance struct {
+			JSON bool `name:"json" help:"print accounts as json"`
+		} `cmd:"balance" help:"Show flexi-time, monthly and vacation accounts"`
+
+		Doctor struct {
+			Archive string `name:"archive" short:"a" completion:"file" help:"write a redacted debug archive to attach to bug reports"`
+		} `cmd:"doctor" help:"Check compatibility with your Matrix server"`
+
+		Reminders struct {
+			List struct {
+			} `cmd:"list" default:"1" help:"List pending reminders"`
+			Clear struct {
+			} `cmd:"clear" help:"Remove all pending reminders"`
+			Watch struct {
+				Interval time.Duration `name:"interval" default:"30s" help:"polling interval for due reminders"`
+			} `cmd:"watch" help:"Fire reminders of the daemon scheduler"`
+			Notify struct {
+				Milestone string `name:"milestone" default:"manual" help:"name of the milestone"`
+				Message   string `arg:"" help:"message to send"`
+			} `cmd:"notify" hidden:"" help:"Send a reminder using the configured notifiers"`
+		} `cmd:"reminders" help:"Manage go-home reminders"`
+
+		Daemon struct {
+			TargetTime  string        `name:"target-time" short:"t" completion:"time" help:"target time in format '15:04', defaults to user config or 08:00"`
+			Refresh     time.Duration `name:"refresh" default:"10m" help:"interval to refresh entries from Matrix"`
+			Socket      string        `name:"socket" help:"path of the JSON-RPC socket, defaults to $XDG_RUNTIME_DIR/gohome.sock"`
+			NoDBus      bool          `name:"no-dbus" help:"do not export the D-Bus interface"`
+			NoReminders bool          `name:"no-reminders" help:"do not fire reminders from the daemon"`
+		} `cmd:"daemon" help:"Keep status in memory and serve it via Unix socket and D-Bus"`
+
+		Serve struct {
+			Listen     string        `name:"listen" default:"127.0.0.1:8099" help:"address to listen on"`
+			TargetTime string        `name:"target-time" short:"t" completion:"time" help:"target time in format '15:04', defaults to user config or 08:00"`
+			Refresh    time.Duration `name:"refresh" default:"10m" help:"interval to refresh entries from Matrix"`
+		} `cmd:"serve" help:"Serve status as HTML page, JSON and Prometheus metrics"`
+
+		Status struct {
+			Format           string `name:"format" short:"f" default:"plain" enum:"plain,waybar,i3blocks,polybar,tmux,xbar" help:"output format: plain, waybar, i3blocks, polybar, tmux or xbar"`
+			TargetTime       string `name:"target-time" short:"t" completion:"time" help:"target time in format '15:04', defaults to user config or 08:00"`
+			CacheTimeSeconds int    `name:"cache-time" default:"600" help:"max cache age in seconds before Matrix is queried"`
+		} `cmd:"status" help:"Print a one-line status for status bars"`
+
+		Export struct {
+			From       string `name:"from" help:"first day in format '2006-01-02', defaults to the first day of the current month"`
+			To         string `name:"to" help:"last day in format '2006-01-02', defaults to today"`
+			Format     string `name:"format" short:"f" default:"csv" enum:"csv,ics,xlsx,json" help:"output format: csv, ics, xlsx or json"`
+			Output     string `name:"output" short:"o" completion:"file" help:"output file, defaults to stdout"`
+			Fetch      bool   `name:"fetch" help:"fetch days missing in the history from Matrix"`
+			TargetTime string `name:"target-time" short:"t" completion:"time" help:"target time in format '15:04', defaults to user config or 08:00"`
+		} `cmd:"export" help:"Export per-day work times as timesheet"`
+
+		Reconcile struct {
+			Month      string `name:"month" short:"m" help:"month in format '2006-01', defaults to the current month"`
+			TargetTime string `name:"target-time" short:"t" completion:"time" help:"target time in format '15:04', defaults to user config or 08:00"`
+			All        bool   `name:"all" short:"a" help:"list matching days as well"`
+		} `cmd:"reconcile" help:"Compare gohome's accounting with the monthly reconciliation of Matrix"`
+
+		Stats struct {
+			Period     string `name:"period" short:"p" default:"month" enum:"week,month,year" help:"current week, month or year until today"`
+			From       string `name:"from" help:"first day in format '2006-01-02', overrides the period"`
+			To         string `name:"to" help:"last day in format '2006-01-02', defaults to today"`
+			TargetTime string `name:"target-time" short:"t" completion:"time" help:"target time in format '15:04', defaults to user config or 08:00"`
+			Fetch      bool   `name:"fetch" help:"fetch days missing in the history from Matrix"`
+			ASCII      bool   `name:"ascii" help:"draw charts with ASCII characters only"`
+		} `cmd:"stats" help:"Show statistics and charts of past days from the history"`
+
+		Compliance struct {
+			Period string `name:"period" short:"p" default:"month" enum:"week,month,year" help:"current week, month or year until today"`
+			From   string `name:"from" help:"first day in format '2006-01-02', overrides the period"`
+			To     string `name:"to" help:"last day in format '2006-01-02', defaults to today"`
+			Fetch  bool   `name:"fetch" help:"fetch days missing in the history from Matrix"`
+		} `cmd:"compliance" help:"Check rest periods, maximum work times and breaks of past days"`
+
+		Plan struct {
+			Bookings         []string `arg:"" optional:"" help:"hypothetical bookings like 'break 12:00-12:40, leave 17:15' or 'trip 14:00-15:30'"`
+			Interactive      bool     `name:"interactive" short:"i" help:"adjust the simulated bookings with arrow keys"`
+			Empty            bool     `name:"empty" help:"ignore today's actual bookings"`
+			TargetTime       string   `name:"target-time" short:"t" completion:"time" help:"target time in format '15:04', defaults to user config or 08:00"`
+			CacheTimeSeconds int      `name:"cache-time" default:"600" help:"max cache age in seconds"`
+		} `cmd:"plan" help:"Simulate bookings and show the resulting work time, flexi-time and compliance issues"`
+
+		Completion struct {
+			Shell string `arg:"" enum:"bash,zsh,fish" help:"shell to print the completion script for: bash, zsh or fish"`
+		} `cmd:"completion" help:"Print a shell completion script"`
+
+		Man struct {
+		} `cmd:"man" help:"Print the manual page in roff format"`
+
+		Theme struct {
+			List struct {
+			} `cmd:"list" default:"1" help:"List built-in themes"`
+			Preview struct {
+				Themes []string `arg:"" optional:"" completion:"theme" help:"built-in themes or theme files, defaults to all built-in themes"`
+			} `cmd:"preview" help:"Show sample output in the given themes"`
+			Apply struct {
+				Theme string `arg:"" completion:"theme" help:"built-in theme or theme file"`
+			} `cmd:"apply" help:"Validate a theme and write it to colors.json"`
+		} `cmd:"theme" help:"List, preview and apply color themes"`
+
+		Version struct {
+			Remote bool `name:"remote" help:"log in and print the version of the Matrix server as well"`
+		} `cmd:"version" help:"Print version, commit, build date and Go version"`
+	}
+
+	cliOptions = []kong.Option{
+		kong.Name("gohome"),
+		kong.Description("Show current worktime and possible leave times with Matrix integration."),
+		kong.Vars{"version": readBuildInfo().Short()},
 	}
+	// cliModel is the parsed CLI model used to generate completion scripts and the manual page.
+	cliModel *kong.Application
 
 	currentState EntryType
 )
 
 func main() {
-	ctx := kong.Parse(&cli)
-	if err := execCmd(ctx.Command()); err != nil {
-		fmt.Println("ERR:", err)
+	ctx := kong.Parse(&cli, cliOptions...)
+	cliModel = ctx.Model
+	closeLog, err := configureLogging()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", stdio.LevelName(slog.LevelError), err.Error())
+		os.Exit(1)
+	}
+
+	err = execCmd(ctx.Command())
+	if err != nil {
+		stdio.Error("%s", err.Error())
+	}
+	closeLog()
+	if err != nil {
+		if len(cli.LogFile) > 0 {
+			// fatal errors are shown on the terminal even if everything else is logged to a file
+			fmt.Fprintf(os.Stderr, "[%s] %s\n", stdio.LevelName(slog.LevelError), err.Error())
+		}
 		os.Exit(1)
 	}
 }
 
+// configureLogging applies the log flags. Verbose and debug output lower the default level to debug.
+func configureLogging() (func() error, error) {
+	defaultLevel := slog.LevelInfo
+	if cli.Verbose || cli.Debug {
+		defaultLevel = slog.LevelDebug
+	}
+	level, levels, err := stdio.ParseLogLevels(cli.LogLevel, defaultLevel)
+	if err != nil {
+		return nil, err
+	}
+	return stdio.ConfigureLogging(stdio.LogConfig{File: cli.LogFile, Format: cli.LogFormat, Level: level, Levels: levels})
+}
+
 func execCmd(cmd string) error {
 	if cli.Debug {
-		cli.Verbose = true
-		matrixDebugPrint = true
 		matrixOutputFiles = true
-		matrixOutputFileDir = "."
+		matrixOutputFileDir = cli.DumpDir
+		if usrConf, err := ReadUserConfig(); err == nil {
+			matrixRedactTerms = usrConf.RedactTerms
+		}
 	}
-	stdio.Verbose = cli.Verbose
+	stdio.ColorMode = cli.Color
 
 	initColors()
 
@@ -60,6 +224,72 @@ func execCmd(cmd string) error {
 	case "dump-colors":
 		return dumpColors()
 
+	case "clock <action>":
+		return cmdClock()
+
+	case "correct":
+		return cmdCorrect()
+
+	case "balance":
+		return cmdBalance()
+
+	case "doctor":
+		return cmdDoctor()
+
+	case "reminders list":
+		return cmdRemindersList()
+
+	case "reminders clear":
+		return cmdRemindersClear()
+
+	case "reminders watch":
+		return cmdRemindersWatch()
+
+	case "reminders notify <message>":
+		return cmdRemindersNotify()
+
+	case "daemon":
+		return cmdDaemon()
+
+	case "serve":
+		return cmdServe()
+
+	case "status":
+		return cmdStatus()
+
+	case "export":
+		return cmdExport()
+
+	case "reconcile":
+		return cmdReconcile()
+
+	case "stats":
+		return cmdStats()
+
+	case "compliance":
+		return cmdCompliance()
+
+	case "plan", "plan <bookings>":
+		return cmdPlan()
+
+	case "completion <shell>":
+		return cmdCompletion()
+
+	case "man":
+		return cmdMan()
+
+	case "theme list":
+		return cmdThemeList()
+
+	case "theme preview", "theme preview <themes>":
+		return cmdThemePreview()
+
+	case "theme apply <theme>":
+		return cmdThemeApply()
+
+	case "version":
+		return cmdVersion()
+
 	default:
 		return fmt.Errorf("unknown command %q", cmd)
 	}
@@ -113,60 +343,23 @@ func process() error {
 		}
 	}
 
-	stdio.Debug("target time is %v", targetTime)
+	worktimeLog.Debug("target time", "duration", targetTime)
 
-	var entries []Entry
-	var flexiTimeBalance time.Duration
-	var cacheTime time.Time
-	var cacheOK bool
-	if !cli.Show.ForceReload {
-		stdio.Debug("read cache")
-		var err error
-		entries, flexiTimeBalance, cacheTime, cacheOK, err = ReadCache()
-		if err != nil {
-			stdio.Warn("read cache failed: %s", err.Error())
-		} else if cacheOK {
-			if len(entries) == 0 {
-				stdio.Debug("no entries in cache, force update")
-				cacheOK = false
-			} else {
-				if entries[len(entries)-1].Type != EntryTypeCome {
-					stdio.Debug("latest entry in cache is %q, force update", entries[len(entries)-1].Type)
-					cacheOK = false
-				} else {
-					stdio.Debug("cache is valid")
-				}
-			}
-		}
+	templateName := cli.Show.Template
+	if len(templateName) == 0 {
+		templateName = usrConf.ShowTemplate
+	}
+	tmpl, err := loadShowTemplate(templateName)
+	if err != nil {
+		return err
 	}
-	if !cacheOK {
-		matrixConfig, err := GetMatrixConfig()
-		if err != nil {
-			return fmt.Errorf("unable to retrieve Matrix configuration: %s", err.Error())
-		}
-
-		if len(matrixConfig.Pass) == 0 {
-			stdio.Println("Please enter Matrix password (it will not be stored locally):")
-			matrixConfig.Pass, err = stdio.ReadPasswordWithPrompt("> ")
-			if err != nil {
-				return fmt.Errorf("unable to retrieve Matrix password: %s", err.Error())
-			}
-		}
-
-		stdio.Debug("fetch matrix entries")
-		entries, flexiTimeBalance, err = FetchMatrixEntries(matrixConfig)
-		if err != nil {
-			return err
-		}
 
-		if err := WriteCache(entries, flexiTimeBalance); err != nil {
-			stdio.Warn("write cache failed: %s", err.Error())
-		} else {
-			stdio.Debug("cache written")
-		}
+	entries, flexiTimeBalance, cacheTime, cacheOK, err := loadEntries(cli.Show.ForceReload, time.Duration(cli.Show.CacheTimeSeconds)*time.Second)
+	if err != nil {
+		return err
 	}
 
-	stdio.Debug("entry count: %d", len(entries))
+	worktimeLog.Debug("entries", "count", len(entries))
 	if len(entries) > 0 {
 		currentState = entries[len(entries)-1].Type
 
@@ -181,21 +374,9 @@ func process() error {
 			entries = append(entries, Entry{Type: EntryTypeLeave, Time: leaveTime})
 		}
 
-		for _, entry := range entries {
-			if entry.Type == EntryTypeCome {
-				stdio.Println(" %s--> %s%s", colors.ComeEntry, entry.Time.Format("15:04"), colorEnd)
-			} else if entry.Type == EntryTypeLeave {
-				stdio.Println(" %s<-- %s%s", colors.LeaveEntry, entry.Time.Format("15:04"), colorEnd)
-			} else if entry.Type == EntryTypeTrip {
-				stdio.Println(" %s<-- %s DG%s", colors.TripEntry, entry.Time.Format("15:04"), colorEnd)
-			}
-		}
-
-		workTime, startTime, breakTime, err := ComputeWorkTime(entries)
-		if err != nil {
-			return err
-		}
+		printEntryGaps(entries)
 
+		var simulatedBreakTime *time.Duration
 		if len(cli.Show.BreakTime) > 0 {
 			t, err := time.Parse("15:04", cli.Show.BreakTime)
 			if err != nil {
@@ -203,81 +384,69 @@ func process() error {
 			}
 
 			newBreakTime := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
-			diff := (breakTime - newBreakTime)
-			breakTime = newBreakTime
-			workTime += diff
+			simulatedBreakTime = &newBreakTime
 		}
 
-		accountedWorkTime, accountedBreakTime, err := ComputeAccountedWorkTime(workTime, breakTime)
+		status, err := ComputeStatus(entries, flexiTimeBalance, targetTime, simulatedBreakTime)
 		if err != nil {
 			return err
 		}
 
-		stdio.Println("-----------------------------------------------------")
 		if cacheOK {
-			stdio.Println("time now:            %s %s(cache from %s)%s", time.Now().Format("15:04"), colors.CacheHint, cacheTime.Format("15:04:05"), colorEnd)
-		} else {
-			stdio.Println("time now:            %s", time.Now().Format("15:04"))
+			status.CacheTime = cacheTime
 		}
-		flexiTime := noSeconds(accountedWorkTime) - targetTime
-		stdio.Println("worktime:            %s%s%s (%s)", colors.WorkTime, formatDurationSeconds(accountedWorkTime), colorEnd, formatFlexiTime(flexiTime))
-		if noSeconds(accountedBreakTime) != noSeconds(breakTime) {
-			stdio.Println("%sbreak:               %s (taken %s)%s", colors.BreakEntry, formatDurationMinutes(accountedBreakTime), formatDurationMinutes(breakTime), colorEnd)
-		} else {
-			stdio.Println("%sbreak:               %s%s", colors.BreakEntry, formatDurationMinutes(accountedBreakTime), colorEnd)
-		}
-
-		newFlexiTimeBalance := flexiTimeBalance + flexiTime
-		stdio.Println("flexi-time balance: %s -> %s", formatFlexiTime(flexiTimeBalance), formatFlexiTime(newFlexiTimeBalance))
-
-		t1, err := GetLeaveTime(startTime, breakTime, 6*time.Hour)
-		if err != nil {
-			return err
+		data := showData{Status: status, Now: time.Now(), Cached: cacheOK}
+		tomorrowTarget := targetTime
+		if len(cli.Show.TomorrowTarget) > 0 {
+			tomorrowTarget, err = parseDurationHHMM(cli.Show.TomorrowTarget)
+			if err != nil {
+				return fmt.Errorf("failed to parse tomorrow's target time: %s", err.Error())
+			}
 		}
-		t2, err := GetLeaveTime(startTime, breakTime, targetTime)
-		if err != nil {
-			return err
+		if plan, ok := PlanTomorrow(usrConf.Compliance, status, tomorrowTarget); ok && (plan.Restricted || len(cli.Show.TomorrowTarget) > 0) {
+			data.Tomorrow = plan
 		}
-		t3, err := GetLeaveTime(startTime, breakTime, 9*time.Hour)
-		if err != nil {
-			return err
+		if r, ok := RecommendBreak(status, data.Now); ok {
+			data.Break = r
 		}
-		t4, err := GetLeaveTime(startTime, breakTime, 10*time.Hour)
-		if err != nil {
+		if err := renderShowTemplate(os.Stdout, tmpl, data); err != nil {
 			return err
 		}
-
-		breakTime1 := t1.Sub(startTime) - (6 * time.Hour)
-		breakTime2 := t2.Sub(startTime) - targetTime
-		breakTime3 := t3.Sub(startTime) - (9 * time.Hour)
-		breakTime4 := t4.Sub(startTime) - (10 * time.Hour)
-
-		stdio.Println("-----------------------------------------------------")
-		stdio.Println("06:00 at %s %s(%s break)%s", t1.Format("15:04"), colors.BreakInfo, formatDurationMinutes(breakTime1), colorEnd)
-		stdio.Println("09:00 at %s %s(%s break)%s", t3.Format("15:04"), colors.BreakInfo, formatDurationMinutes(breakTime3), colorEnd)
-		stdio.Println("10:00 at %s %s(%s break)%s", t4.Format("15:04"), colors.BreakInfo, formatDurationMinutes(breakTime4), colorEnd)
-		stdio.Println("-----------------------------------------------------")
-		stdio.Println("go home (%s) at %s%s%s %s(%s break)%s", formatDurationMinutes(targetTime), colors.LeaveTime, t2.Format("15:04"), colorEnd, colors.BreakInfo, formatDurationMinutes(breakTime2), colorEnd)
+		printComplianceWarnings(usrConf.Compliance, entries)
 
 		if cli.Show.SetReminder {
-			if err := goat.ClearQueue("g"); err != nil {
-				return fmt.Errorf("clear job queue: %s", err.Error())
+			reminders, err := SetReminders(usrConf.Reminders, status.StartTime, status.BreakTime, targetTime)
+			if err != nil {
+				return fmt.Errorf("set reminders: %s", err.Error())
 			}
-			if _, err := goat.AddJob("notify-send -i error 'Go home!'", t2, "g"); err != nil {
-				return fmt.Errorf("add gohome job: %s", err.Error())
+			stdio.Println("-----------------------------------------------------")
+			if len(reminders) == 0 {
+				stdio.Println("-> no upcoming reminders")
 			}
-			t5 := t4.Add(-15 * time.Minute)
-			if _, err := goat.AddJob("notify-send -i error '10h-Limit in 15 min! GO HOME!'", t5, "g"); err != nil {
-				return fmt.Errorf("add 10h warning job: %s", err.Error())
+			for _, r := range reminders {
+				stdio.Println("-> reminder %q has been set to %s", r.Milestone, r.Time.Format("15:04"))
 			}
-			stdio.Println("-----------------------------------------------------")
-			stdio.Println("-> reminders have been set to %d:%d and %d:%d", t2.Hour(), t2.Minute(), t5.Hour(), t5.Minute())
 		}
 	}
 
 	return nil
 }
 
+func getMatrixConfigWithPassword() (MatrixConfig, error) {
+	matrixConfig, err := GetMatrixConfig()
+	if err != nil {
+		return MatrixConfig{}, fmt.Errorf("unable to retrieve Matrix configuration: %s", err.Error())
+	}
+
+	if len(matrixConfig.Pass) == 0 {
+		matrixConfig.Pass, err = lookupMatrixPassword(matrixConfig)
+		if err != nil {
+			return MatrixConfig{}, fmt.Errorf("unable to retrieve Matrix password: %s", err.Error())
+		}
+	}
+	return matrixConfig, nil
+}
+
 func noSeconds(t time.Duration) time.Duration {
 	return time.Duration(int(t.Minutes())) * time.Minute
 }
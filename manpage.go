@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alecthomas/kong"
+)
+
+// roffEscape escapes backslashes, dashes and leading control characters for roff.
+func roffEscape(str string) string {
+	str = strings.ReplaceAll(str, "\\", "\\e")
+	str = strings.ReplaceAll(str, "-", "\\-")
+	if strings.HasPrefix(str, ".") || strings.HasPrefix(str, "'") {
+		str = "\\&" + str
+	}
+	return str
+}
+
+func manPlaceHolder(f *kong.Flag) string {
+	if len(f.PlaceHolder) > 0 {
+		return f.PlaceHolder
+	}
+	return strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+}
+
+func writeManFlags(w io.Writer, flags []*kong.Flag) {
+	for _, f := range flags {
+		names := make([]string, 0, 2)
+		for _, n := range flagNames(f) {
+			names = append(names, `\fB`+roffEscape(n)+`\fR`)
+		}
+		fmt.Fprintln(w, ".TP")
+		if takesValue(f) {
+			fmt.Fprintf(w, "%s \\fI%s\\fR\n", strings.Join(names, ", "), roffEscape(manPlaceHolder(f)))
+		} else {
+			fmt.Fprintln(w, strings.Join(names, ", "))
+		}
+		help := f.Help
+		if f.HasDefault && len(f.Default) > 0 {
+			help += fmt.Sprintf(" (default: %s)", f.Default)
+		}
+		fmt.Fprintln(w, roffEscape(help))
+	}
+}
+
+// writeManPage writes the manual page of the application in roff format.
+func writeManPage(w io.Writer, app *kong.Application) {
+	commands := completionCommands(app)
+	name := app.Name
+
+	fmt.Fprintf(w, ".TH %s 1 \"\" \"%s\" \"User Commands\"\n", strings.ToUpper(name), name)
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintf(w, "%s \\- %s\n", name, roffEscape(strings.TrimSuffix(app.Help, ".")))
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintf(w, ".B %s\n[\\fIflags\\fR] \\fIcommand\\fR [\\fIargs\\fR]\n", name)
+	fmt.Fprintln(w, ".SH DESCRIPTION")
+	fmt.Fprintf(w, "%s shows today's bookings from Matrix, the accounted work time and possible leave times. Without command, \\fBshow\\fR is executed.\n", name)
+	fmt.Fprintln(w, ".SH FLAGS")
+	writeManFlags(w, commands[0].Flags)
+	fmt.Fprintln(w, ".SH COMMANDS")
+	for _, cmd := range commands[1:] {
+		usage := name + " " + cmd.Path
+		for _, arg := range cmd.Args {
+			usage += " " + arg.Summary()
+		}
+		fmt.Fprintf(w, ".SS \"%s\"\n", roffEscape(usage))
+		fmt.Fprintln(w, roffEscape(cmd.Help))
+		writeManFlags(w, cmd.Flags)
+	}
+	fmt.Fprintln(w, ".SH FILES")
+	fmt.Fprintln(w, ".TP")
+	fmt.Fprintln(w, "\\fI~/.config/gohome/userconfig.json\\fR")
+	fmt.Fprintln(w, "User settings like target time, show template, reminders and compliance rules.")
+	fmt.Fprintln(w, ".TP")
+	fmt.Fprintln(w, "\\fI~/.config/gohome/colors.json\\fR")
+	fmt.Fprintln(w, "Output colors, written by \\fBdump\\-colors\\fR.")
+	fmt.Fprintln(w, ".TP")
+	fmt.Fprintln(w, "\\fI~/.config/gohome/history.json\\fR")
+	fmt.Fprintln(w, "Bookings of past days used by \\fBexport\\fR, \\fBstats\\fR and \\fBcompliance\\fR.")
+	fmt.Fprintln(w, ".SH SEE ALSO")
+	fmt.Fprintln(w, "https://github.com/sbreitf1/gohome")
+}
+
+func cmdMan() error {
+	writeManPage(os.Stdout, cliModel)
+	return nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// AccountUnitDuration denotes an account measured in hours and minutes.
+	AccountUnitDuration AccountUnit = "duration"
+	// AccountUnitDays denotes an account measured in (fractional) days.
+	AccountUnitDays AccountUnit = "days"
+
+	// AccountFlexiTime is the flexi-time balance of the previous day.
+	AccountFlexiTime = "flexi_time"
+	// AccountMonthlyTarget is the target work time of the current month.
+	AccountMonthlyTarget = "monthly_target"
+	// AccountMonthlyActual is the accounted work time of the current month.
+	AccountMonthlyActual = "monthly_actual"
+	// AccountOvertime is the overtime account.
+	AccountOvertime = "overtime"
+	// AccountVacationEntitlement is the vacation entitlement of the current year.
+	AccountVacationEntitlement = "vacation_entitlement"
+	// AccountVacationRemaining is the number of remaining vacation days.
+	AccountVacationRemaining = "vacation_remaining"
+)
+
+var (
+	// matrixAccountDefs describe all known accounts with their localized labels.
+	matrixAccountDefs = []matrixAccountDef{
+		{Name: AccountFlexiTime, Title: "flexi-time balance", Unit: AccountUnitDuration, Labels: map[string][]string{
+			"en": {"Balance previous day", "Flexitime balance"},
+			"de": {"Saldo Vortag", "Gleitzeitsaldo"},
+		}},
+		{Name: AccountMonthlyTarget, Title: "monthly target", Unit: AccountUnitDuration, Labels: map[string][]string{
+			"en": {"Target time", "Target hours", "Monthly target"},
+			"de": {"Sollzeit", "Monatssoll"},
+		}},
+		{Name: AccountMonthlyActual, Title: "monthly actual", Unit: AccountUnitDuration, Labels: map[string][]string{
+			"en": {"Actual time", "Actual hours", "Monthly actual"},
+			"de": {"Istzeit", "Monatsist"},
+		}},
+		{Name: AccountOvertime, Title: "overtime", Unit: AccountUnitDuration, Labels: map[string][]string{
+			"en": {"Overtime", "Overtime account"},
+			"de": {"Überstunden", "Mehrarbeit", "Überstundenkonto"},
+		}},
+		{Name: AccountVacationEntitlement, Title: "vacation entitlement", Unit: AccountUnitDays, Labels: map[string][]string{
+			"en": {"Vacation entitlement", "Annual leave entitlement"},
+			"de": {"Urlaubsanspruch", "Jahresurlaub"},
+		}},
+		{Name: AccountVacationRemaining, Title: "vacation remaining", Unit: AccountUnitDays, Labels: map[string][]string{
+			"en": {"Remaining vacation", "Vacation remaining", "Remaining leave"},
+			"de": {"Resturlaub", "Urlaub Rest", "Urlaubsrest"},
+		}},
+	}
+
+	// patternMatrixDays matches the number at the end of values like "Urlaub 2026: 12,5 Tage".
+	patternMatrixDays = regexp.MustCompile(`(?i)(-?\d+(?:[.,]\d+)?)\s*(?:tage?|days?|d)?\.?\s*$`)
+)
+
+// AccountUnit denotes how the value of an account is measured.
+type AccountUnit string
+
+type matrixAccountDef struct {
+	Name   string
+	Title  string
+	Unit   AccountUnit
+	Labels map[string][]string
+}
+
+// LabelsFor returns all labels with the labels of the given locale first, followed by the other locales in alphabetical order.
+func (def matrixAccountDef) LabelsFor(locale string) []string {
+	lang := strings.SplitN(locale, "-", 2)[0]
+	labels := append([]string{}, def.Labels[lang]...)
+	for _, l := range sortedKeys(def.Labels) {
+		if l != lang {
+			labels = append(labels, def.Labels[l]...)
+		}
+	}
+	return labels
+}
+
+// MatrixAccount is a time or vacation account read from Matrix.
+type MatrixAccount struct {
+	Name     string
+	Title    string
+	Label    string
+	Unit     AccountUnit
+	Duration time.Duration
+	Days     float64
+}
+
+// String returns the formatted value of the account.
+func (a MatrixAccount) String() string {
+	if a.Unit == AccountUnitDays {
+		return strconv.FormatFloat(a.Days, 'f', -1, 64) + " days"
+	}
+	return formatSignedDurationMinutes(a.Duration)
+}
+
+// MarshalJSON encodes durations as minutes and days as numbers.
+func (a MatrixAccount) MarshalJSON() ([]byte, error) {
+	type jsonAccount struct {
+		Name    string      `json:"name"`
+		Title   string      `json:"title"`
+		Label   string      `json:"label"`
+		Unit    AccountUnit `json:"unit"`
+		Minutes *int64      `json:"minutes,omitempty"`
+		Days    *float64    `json:"days,omitempty"`
+		Text    string      `json:"text"`
+	}
+	ja := jsonAccount{Name: a.Name, Title: a.Title, Label: a.Label, Unit: a.Unit, Text: a.String()}
+	if a.Unit == AccountUnitDays {
+		ja.Days = &a.Days
+	} else {
+		minutes := int64(a.Duration / time.Minute)
+		ja.Minutes = &minutes
+	}
+	return json.Marshal(ja)
+}
+
+// MatrixBalance contains all accounts read from Matrix.
+type MatrixBalance struct {
+	Time     time.Time       `json:"time"`
+	Accounts []MatrixAccount `json:"accounts"`
+}
+
+// Get returns the account with the given name.
+func (b MatrixBalance) Get(name string) (MatrixAccount, bool) {
+	for _, a := range b.Accounts {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return MatrixAccount{}, false
+}
+
+// GetBalance returns all accounts available on the monthly reconciliation and time accounts pages.
+func (c *MatrixClient) GetBalance() (MatrixBalance, error) {
+	page, err := c.visitMonthlyReconciliation()
+	if err != nil {
+		return MatrixBalance{}, err
+	}
+	balance := MatrixBalance{Time: time.Now(), Accounts: page.Accounts()}
+	if _, ok := balance.Get(AccountFlexiTime); !ok {
+		return MatrixBalance{}, fmt.Errorf("unable to parse current flexi-time balance")
+	}
+
+	if c.HasMenuItem(matrixMenuItemTimeAccounts) {
+		page, err := c.visitMenuItem(matrixMenuItemTimeAccounts)
+		if err != nil {
+			return MatrixBalance{}, fmt.Errorf("visit time accounts: %s", err.Error())
+		}
+		if err := c.dump.Page("accounts", page); err != nil {
+			return MatrixBalance{}, err
+		}
+		for _, a := range page.Accounts() {
+			if _, ok := balance.Get(a.Name); !ok {
+				balance.Accounts = append(balance.Accounts, a)
+			}
+		}
+	}
+	return balance, nil
+}
+
+// Accounts returns all known accounts found on the page.
+func (p *matrixPage) Accounts() []MatrixAccount {
+	locale, _ := p.Locale()
+
+	accounts := make([]MatrixAccount, 0)
+	for _, def := range matrixAccountDefs {
+		for _, label := range def.LabelsFor(locale) {
+			text, ok := p.LabeledValue(label)
+			if !ok {
+				continue
+			}
+			account := MatrixAccount{Name: def.Name, Title: def.Title, Label: label, Unit: def.Unit}
+			var err error
+			if def.Unit == AccountUnitDays {
+				account.Days, err = parseMatrixDays(text)
+			} else {
+				account.Duration, err = parseMatrixDuration(text)
+			}
+			if err == nil {
+				accounts = append(accounts, account)
+				break
+			}
+		}
+	}
+	return accounts
+}
+
+// LabeledValue returns the text of the last value labeled with the given text. Values are either marked by a title attribute or follow their label.
+func (p *matrixPage) LabeledValue(label string) (string, bool) {
+	titled := p.doc.Find("[title]").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return normalizeMatrixText(s.AttrOr("title", "")) == normalizeMatrixText(label)
+	})
+	if titled.Length() > 0 {
+		cell := titled.Last()
+		if children := cell.Children(); children.Length() > 0 {
+			cell = children.First()
+		}
+		return strings.TrimSpace(cell.Text()), true
+	}
+
+	labels := p.doc.Find("th, td, label, span, div, dt").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return s.Children().Length() == 0 && normalizeMatrixText(strings.TrimSuffix(strings.TrimSpace(s.Text()), ":")) == normalizeMatrixText(label)
+	})
+	if labels.Length() == 0 {
+		return "", false
+	}
+	value := labels.Last().Next()
+	if value.Length() == 0 {
+		// label wrapped into a container like <td><span>Label</span></td>
+		value = labels.Last().Parent().Next()
+	}
+	if value.Length() == 0 {
+		return "", false
+	}
+	return strings.TrimSpace(value.Text()), true
+}
+
+// parseMatrixDays parses day counts like "12,5" or "3 days".
+func parseMatrixDays(str string) (float64, error) {
+	m := patternMatrixDays.FindStringSubmatch(str)
+	if len(m) != 2 {
+		return 0, fmt.Errorf("unexpected day format %q", str)
+	}
+	return strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64)
+}
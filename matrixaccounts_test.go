@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrixPageAccounts(t *testing.T) {
+	page, err := parseMatrixPage(`<html lang="de"><body>
+<table>
+<tr><td title="Saldo Vortag"><span>1:00</span></td></tr>
+<tr><td title="Saldo Vortag"><span>-0:45</span></td></tr>
+</table>
+<table>
+<tr><th>Sollzeit</th><td>160:00</td></tr>
+<tr><td><span>Istzeit:</span></td><td>84:30</td></tr>
+</table>
+<dl><dt>Resturlaub</dt><dd>12,5 Tage</dd></dl>
+</body></html>`)
+	require.NoError(t, err)
+
+	accounts := page.Accounts()
+	assert.Equal(t, []MatrixAccount{
+		{Name: AccountFlexiTime, Title: "flexi-time balance", Label: "Saldo Vortag", Unit: AccountUnitDuration, Duration: -dur(0, 45)},
+		{Name: AccountMonthlyTarget, Title: "monthly target", Label: "Sollzeit", Unit: AccountUnitDuration, Duration: dur(160, 0)},
+		{Name: AccountMonthlyActual, Title: "monthly actual", Label: "Istzeit", Unit: AccountUnitDuration, Duration: dur(84, 30)},
+		{Name: AccountVacationRemaining, Title: "vacation remaining", Label: "Resturlaub", Unit: AccountUnitDays, Days: 12.5},
+	}, accounts)
+}
+
+func TestParseMatrixDays(t *testing.T) {
+	for str, expected := range map[string]float64{"7": 7, "12,5 Tage": 12.5, "Urlaub 2026: 12,5 Tage": 12.5, "-1.5 days": -1.5, "3d": 3} {
+		days, err := parseMatrixDays(str)
+		require.NoError(t, err, str)
+		assert.Equal(t, expected, days, str)
+	}
+	_, err := parseMatrixDays("2026 Tage offen")
+	assert.Error(t, err)
+}
+
+func TestMatrixPageAccountsShortLabels(t *testing.T) {
+	page, err := parseMatrixPage(`<html lang="de"><body>
+<table><tr><th>Tag</th><th>Soll</th><th>Ist</th></tr><tr><td>01.10.</td><td>8:00</td><td>7:30</td></tr></table>
+</body></html>`)
+	require.NoError(t, err)
+	assert.Empty(t, page.Accounts())
+}
+
+func TestMatrixAccountLabelsFor(t *testing.T) {
+	def := matrixAccountDef{Labels: map[string][]string{"fr": {"Solde"}, "de": {"Saldo"}, "en": {"Balance"}}}
+	for range 10 {
+		assert.Equal(t, []string{"Solde", "Saldo", "Balance"}, def.LabelsFor("fr-FR"))
+		assert.Equal(t, []string{"Saldo", "Balance", "Solde"}, def.LabelsFor("pt"))
+	}
+}
+
+func TestMatrixAccountJSON(t *testing.T) {
+	data, err := json.Marshal(MatrixAccount{Name: AccountFlexiTime, Unit: AccountUnitDuration, Duration: -dur(1, 30)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"flexi_time","title":"","label":"","unit":"duration","minutes":-90,"text":"-01:30"}`, string(data))
+
+	data, err = json.Marshal(MatrixAccount{Name: AccountVacationRemaining, Unit: AccountUnitDays, Days: 3})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"vacation_remaining","title":"","label":"","unit":"days","days":3,"text":"3 days"}`, string(data))
+}
+
+func TestGetBalance(t *testing.T) {
+	fm := newFakeMatrix(t)
+	fm.MonthData = `<table><tr><td title="Balance previous day"><span>2:10</span></td></tr>
+<tr><td>Remaining vacation</td><td>7</td></tr></table>`
+
+	client, err := NewMatrixClient(fm.Config())
+	require.NoError(t, err)
+	defer client.Close()
+
+	balance, err := client.GetBalance()
+	require.NoError(t, err)
+	flexiTime, ok := balance.Get(AccountFlexiTime)
+	assert.True(t, ok)
+	assert.Equal(t, dur(2, 10), flexiTime.Duration)
+	vacation, ok := balance.Get(AccountVacationRemaining)
+	assert.True(t, ok)
+	assert.Equal(t, 7.0, vacation.Days)
+}
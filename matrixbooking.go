@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	matrixMenuItemWebTerminal = "tim_webTerminalMss"
+)
+
+var (
+	// matrixBookingButtons contains the localized labels of the web terminal buttons per entry type.
+	matrixBookingButtons = map[EntryType][]string{
+		EntryTypeCome:  {"Kommen", "Arrive", "Come"},
+		EntryTypeLeave: {"Gehen", "Leave"},
+		EntryTypeTrip:  {"Dienstgang", "Business trip"},
+	}
+)
+
+// Book submits a booking of the given type at the Matrix web terminal and returns the submitted form values. With dryRun, the booking is only prepared but not submitted.
+func (c *MatrixClient) Book(entryType EntryType, dryRun bool) (url.Values, error) {
+	labels, ok := matrixBookingButtons[entryType]
+	if !ok {
+		return nil, fmt.Errorf("cannot book entries of type %q", entryType)
+	}
+	if !c.HasMenuItem(matrixMenuItemWebTerminal) {
+		return nil, fmt.Errorf("web terminal not available in self-service menu")
+	}
+
+	page, err := c.visitMenuItem(matrixMenuItemWebTerminal)
+	if err != nil {
+		return nil, fmt.Errorf("visit web terminal: %s", err.Error())
+	}
+	if err := c.dump.Page("terminal", page); err != nil {
+		return nil, err
+	}
+
+	form, err := page.FormWithButton(labels...)
+	if err != nil {
+		return nil, err
+	}
+	values := c.formValues(form)
+	if dryRun {
+		return values, nil
+	}
+
+	result, err := c.submitForm(form)
+	if err != nil {
+		return nil, fmt.Errorf("submit booking: %s", err.Error())
+	}
+	if err := c.dump.Page("booking", result); err != nil {
+		return nil, err
+	}
+	if errs, _ := result.Messages(); len(errs) > 0 {
+		return nil, fmt.Errorf("booking rejected: %s", strings.Join(errs, "; "))
+	}
+	return values, nil
+}
+
+// submitForm posts a form with the current JSF tokens and follows the redirect.
+func (c *MatrixClient) submitForm(form *matrixForm) (*matrixPage, error) {
+	target := c.lastVisitedPage
+	if strings.HasPrefix(form.Action, "/") {
+		target = form.Action
+	}
+	return c.postRedirect(target, c.formValues(form).Encode())
+}
+
+func (c *MatrixClient) formValues(form *matrixForm) url.Values {
+	values := make(url.Values)
+	for k, v := range form.Values {
+		values[k] = append([]string{}, v...)
+	}
+	values.Set("uniqueToken", c.nextUniqueToken)
+	values.Set("javax.faces.ViewState", c.nextViewState)
+	if len(form.ID) > 0 {
+		values.Set(form.ID+"_SUBMIT", "1")
+	}
+	return values
+}
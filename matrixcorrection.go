@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	matrixMenuItemCorrection = "tim_requestBookingCorrectionMss"
+	matrixMenuItemRequests   = "wfl_myRequestsMss"
+
+	// CorrectionPending denotes a correction request that has not been processed yet.
+	CorrectionPending CorrectionState = "pending"
+	// CorrectionApproved denotes an approved correction request.
+	CorrectionApproved CorrectionState = "approved"
+	// CorrectionRejected denotes a rejected correction request.
+	CorrectionRejected CorrectionState = "rejected"
+	// CorrectionUnknown is used for correction requests with unrecognized status.
+	CorrectionUnknown CorrectionState = "unknown"
+)
+
+var (
+	matrixCorrectionDateLabels   = []string{"Date", "Datum", "Buchungsdatum"}
+	matrixCorrectionComeLabels   = []string{"Come", "Arrive", "Kommen"}
+	matrixCorrectionLeaveLabels  = []string{"Leave", "Gehen"}
+	matrixCorrectionReasonLabels = []string{"Reason", "Comment", "Begründung", "Bemerkung", "Kommentar"}
+	matrixCorrectionSubmitLabels = []string{"Submit", "Send request", "Absenden", "Beantragen"}
+
+	matrixRequestDateColumns        = []string{"date", "datum"}
+	matrixRequestDescriptionColumns = []string{"request", "description", "antrag", "beschreibung"}
+	matrixRequestStatusColumns      = []string{"status", "state", "zustand"}
+
+	matrixCorrectionStates = map[CorrectionState][]string{
+		CorrectionApproved: {"approved", "genehmigt", "accepted"},
+		CorrectionRejected: {"rejected", "abgelehnt", "declined"},
+		CorrectionPending:  {"pending", "open", "requested", "offen", "beantragt", "in bearbeitung"},
+	}
+)
+
+// CorrectionRequest contains the bookings to be added for a day.
+type CorrectionRequest struct {
+	Date   time.Time
+	Come   time.Time
+	Leave  time.Time
+	Reason string
+}
+
+// CorrectionState denotes the processing state of a correction request.
+type CorrectionState string
+
+// Correction is a correction request listed by Matrix.
+type Correction struct {
+	Date        string
+	Description string
+	Status      string
+	State       CorrectionState
+}
+
+// RequestCorrection fills and submits the correction form and returns the submitted form values. With dryRun, the form is only filled but not submitted.
+func (c *MatrixClient) RequestCorrection(req CorrectionRequest, dryRun bool) (url.Values, error) {
+	if req.Come.IsZero() && req.Leave.IsZero() {
+		return nil, fmt.Errorf("correction request needs at least a come or leave time")
+	}
+	if !c.HasMenuItem(matrixMenuItemCorrection) {
+		return nil, fmt.Errorf("correction requests not available in self-service menu")
+	}
+
+	page, err := c.visitMenuItem(matrixMenuItemCorrection)
+	if err != nil {
+		return nil, fmt.Errorf("visit correction form: %s", err.Error())
+	}
+	if err := c.dump.Page("correction", page); err != nil {
+		return nil, err
+	}
+
+	form, err := page.FormWithButton(matrixCorrectionSubmitLabels...)
+	if err != nil {
+		return nil, err
+	}
+	locale, _ := page.Locale()
+
+	dateInput, ok := page.InputByLabel(matrixCorrectionDateLabels...)
+	if !ok {
+		return nil, fmt.Errorf("date field not found in correction form")
+	}
+	form.Values.Set(dateInput.AttrOr("name", ""), req.Date.Format(matrixDateLayout(dateInput.AttrOr("placeholder", ""), locale)))
+
+	fields := []struct {
+		Labels []string
+		Time   time.Time
+		Name   string
+	}{
+		{matrixCorrectionComeLabels, req.Come, "come"},
+		{matrixCorrectionLeaveLabels, req.Leave, "leave"},
+	}
+	for _, f := range fields {
+		if f.Time.IsZero() {
+			continue
+		}
+		input, ok := page.InputByLabel(f.Labels...)
+		if !ok {
+			return nil, fmt.Errorf("%s field not found in correction form", f.Name)
+		}
+		form.Values.Set(input.AttrOr("name", ""), f.Time.Format("15:04"))
+	}
+
+	if len(req.Reason) > 0 {
+		input, ok := page.InputByLabel(matrixCorrectionReasonLabels...)
+		if !ok {
+			return nil, fmt.Errorf("reason field not found in correction form")
+		}
+		form.Values.Set(input.AttrOr("name", ""), req.Reason)
+	}
+
+	values := c.formValues(form)
+	if dryRun {
+		return values, nil
+	}
+
+	result, err := c.submitForm(form)
+	if err != nil {
+		return nil, fmt.Errorf("submit correction request: %s", err.Error())
+	}
+	if err := c.dump.Page("correction-result", result); err != nil {
+		return nil, err
+	}
+	if errs, _ := result.Messages(); len(errs) > 0 {
+		return nil, fmt.Errorf("correction request rejected: %s", strings.Join(errs, "; "))
+	}
+	return values, nil
+}
+
+// GetCorrections returns all correction requests listed in the self-service requests overview.
+func (c *MatrixClient) GetCorrections() ([]Correction, error) {
+	if !c.HasMenuItem(matrixMenuItemRequests) {
+		return nil, fmt.Errorf("request overview not available in self-service menu")
+	}
+
+	page, err := c.visitMenuItem(matrixMenuItemRequests)
+	if err != nil {
+		return nil, fmt.Errorf("visit request overview: %s", err.Error())
+	}
+	if err := c.dump.Page("requests", page); err != nil {
+		return nil, err
+	}
+	return page.Corrections()
+}
+
+// Corrections returns the correction requests listed in the first table with a status column.
+func (p *matrixPage) Corrections() ([]Correction, error) {
+	var table *matrixTable
+	p.doc.Find("table tbody").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		t, err := findMatrixTable(s)
+		if err == nil && t.Column(matrixRequestStatusColumns...) >= 0 {
+			table = t
+			return false
+		}
+		return true
+	})
+	if table == nil {
+		return nil, fmt.Errorf("request table not found")
+	}
+
+	dateCol := table.Column(matrixRequestDateColumns...)
+	descCol := table.Column(matrixRequestDescriptionColumns...)
+	statusCol := table.Column(matrixRequestStatusColumns...)
+
+	corrections := make([]Correction, 0)
+	for i := range table.Rows {
+		date, _ := table.Cell(i, dateCol)
+		desc, _ := table.Cell(i, descCol)
+		status, _ := table.Cell(i, statusCol)
+		corrections = append(corrections, Correction{Date: date, Description: desc, Status: status, State: parseCorrectionState(status)})
+	}
+	return corrections, nil
+}
+
+func parseCorrectionState(status string) CorrectionState {
+	status = strings.ToLower(status)
+	for _, state := range []CorrectionState{CorrectionApproved, CorrectionRejected, CorrectionPending} {
+		for _, keyword := range matrixCorrectionStates[state] {
+			if strings.Contains(status, keyword) {
+				return state
+			}
+		}
+	}
+	return CorrectionUnknown
+}
+
+// InputByLabel returns the input or textarea referenced by a label with one of the given texts. Inputs with matching title or placeholder are accepted as well.
+func (p *matrixPage) InputByLabel(labels ...string) (*goquery.Selection, bool) {
+	label := findByLabel(p.doc.Find("label[for]"), labels, func(s *goquery.Selection) string {
+		return strings.TrimSuffix(strings.TrimSpace(s.Text()), ":")
+	})
+	if label != nil {
+		input := p.doc.Find("[id='" + label.AttrOr("for", "") + "']")
+		if input.Length() > 0 {
+			if goquery.NodeName(input) != "input" && goquery.NodeName(input) != "textarea" {
+				// composite components like calendars wrap the actual input
+				input = input.Find("input[name], textarea[name]")
+			}
+			if input.Length() > 0 {
+				return input.First(), true
+			}
+		}
+	}
+
+	input := findByLabel(p.doc.Find("input[name], textarea[name]"), labels, func(s *goquery.Selection) string {
+		return s.AttrOr("title", s.AttrOr("placeholder", ""))
+	})
+	return input, input != nil
+}
+
+// matrixDateLayout returns the Go time layout for a date placeholder like "dd.MM.yyyy", or a default layout for the locale.
+func matrixDateLayout(placeholder, locale string) string {
+	if strings.Contains(placeholder, "yy") {
+		return strings.NewReplacer("yyyy", "2006", "yy", "06", "MM", "01", "dd", "02").Replace(placeholder)
+	}
+	if strings.HasPrefix(locale, "en") {
+		return "01/02/2006"
+	}
+	return "02.01.2006"
+}
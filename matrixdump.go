@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+// matrixDump writes redacted pages and a manifest of all requests for debugging.
+type matrixDump struct {
+	Dir      string
+	Secrets  []string
+	Requests []matrixDumpRequest
+}
+
+// matrixDumpRequest is an entry of the dump manifest.
+type matrixDumpRequest struct {
+	Time     time.Time         `json:"time"`
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	Status   int               `json:"status"`
+	Location string            `json:"location,omitempty"`
+	Form     map[string]string `json:"form,omitempty"`
+	File     string            `json:"file,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+func newMatrixDump(dir string, secrets ...string) *matrixDump {
+	return &matrixDump{Dir: dir, Secrets: secrets}
+}
+
+// AddSecrets registers values that must not appear in any dumped file.
+func (d *matrixDump) AddSecrets(secrets ...string) {
+	if d == nil {
+		return
+	}
+	for _, s := range secrets {
+		if len(s) > 0 {
+			d.Secrets = append(d.Secrets, s)
+		}
+	}
+}
+
+// Request records a request in the manifest. The form body is redacted.
+func (d *matrixDump) Request(method, url, form string, status int, location string, err error) {
+	if d == nil {
+		return
+	}
+	req := matrixDumpRequest{
+		Time:     time.Now(),
+		Method:   method,
+		URL:      url,
+		Status:   status,
+		Location: location,
+	}
+	if len(form) > 0 {
+		req.Form = redactMatrixForm(form)
+	}
+	if err != nil {
+		req.Error = err.Error()
+	}
+	d.Requests = append(d.Requests, req)
+	if err := d.writeManifest(); err != nil {
+		stdio.Warn("write dump manifest failed: %s", err.Error())
+	}
+}
+
+// Page writes a redacted page and links it to the last recorded request.
+func (d *matrixDump) Page(name string, page *matrixPage) error {
+	if d == nil || page == nil {
+		return nil
+	}
+	if token, ok := page.UniqueToken(); ok {
+		d.AddSecrets(token)
+	}
+	if viewState, ok := page.ViewState(); ok {
+		d.AddSecrets(viewState)
+	}
+
+	file := name + ".html"
+	if err := os.MkdirAll(d.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(d.Dir, file), []byte(redactMatrixPage(page.Body, d.Secrets...)), 0600); err != nil {
+		return fmt.Errorf("output %s file: %s", name, err.Error())
+	}
+	if len(d.Requests) > 0 {
+		d.Requests[len(d.Requests)-1].File = file
+	}
+	return d.writeManifest()
+}
+
+func (d *matrixDump) writeManifest() error {
+	data, err := json.MarshalIndent(d.Requests, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(d.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(d.Dir, "manifest.json"), []byte(redactSecrets(string(data), d.Secrets...)), 0600)
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	fakeMatrixBaseURL   = "/matrix-4.4.2"
+	fakeMatrixUser      = "jdoe"
+	fakeMatrixPass      = "secret"
+	fakeMatrixSessionID = "session-4711"
+)
+
+// fakeMatrix emulates the JSF navigation of a Matrix server.
+type fakeMatrix struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mutex     sync.Mutex
+	pages     map[string]string
+	forms     []map[string]string
+	tokenSeq  int
+	OnPost    func(form map[string]string) (string, bool)
+	Bookings  string
+	MonthData string
+	// BookingError is shown as error message instead of accepting a booking at the web terminal.
+	BookingError string
+	// Corrections contains all submitted correction forms.
+	Corrections []map[string]string
+	// History contains booking rows of past days by date in format "02.01.2006".
+	History map[string]string
+	// MenuStatus is returned instead of a redirect when opening the self-service menu if set.
+	MenuStatus int
+}
+
+var fakeMatrixTerminalButtons = map[string]string{
+	"mainbody:terminal:come":  "Arrive",
+	"mainbody:terminal:leave": "Leave",
+	"mainbody:terminal:trip":  "Business trip",
+}
+
+func newFakeMatrix(t *testing.T) *fakeMatrix {
+	fm := &fakeMatrix{
+		t:         t,
+		pages:     make(map[string]string),
+		History:   make(map[string]string),
+		Bookings:  fakeMatrixBookingRows("Arrive", "08:03"),
+		MonthData: `<table><tr><td title="Balance previous day"><span>1:30</span></td></tr></table>`,
+	}
+	fm.server = httptest.NewServer(http.HandlerFunc(fm.handle))
+
+	oldVersionURL := matrixVersionURL
+	t.Cleanup(func() {
+		fm.server.Close()
+		matrixVersionURL = oldVersionURL
+	})
+	return fm
+}
+
+// Config returns a Matrix config pointing to the fake server.
+func (fm *fakeMatrix) Config() MatrixConfig {
+	return MatrixConfig{Host: fm.server.URL, User: fakeMatrixUser, Pass: fakeMatrixPass}
+}
+
+// Forms returns all submitted forms in order.
+func (fm *fakeMatrix) Forms() []map[string]string {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+	return append([]map[string]string{}, fm.forms...)
+}
+
+func (fm *fakeMatrix) handle(w http.ResponseWriter, r *http.Request) {
+	fm.mutex.Lock()
+	defer fm.mutex.Unlock()
+
+	if r.Method == http.MethodGet && r.URL.Path == "/matrix/login.jspx" {
+		w.Header().Set("Location", fakeMatrixBaseURL+"/login.jspx")
+		w.WriteHeader(http.StatusFound)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			fm.t.Errorf("parse form: %s", err.Error())
+		}
+		form := make(map[string]string)
+		for k := range r.PostForm {
+			form[k] = r.PostForm.Get(k)
+		}
+		fm.forms = append(fm.forms, form)
+
+		if r.URL.Path == fakeMatrixBaseURL+"/login.jspx" {
+			if form["userid"] != fakeMatrixUser || form["password"] != fakeMatrixPass {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: matrixSessionCookieName, Value: fakeMatrixSessionID})
+			w.Header().Set("Location", "afterLogin.jsf")
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+
+		if cookie, err := r.Cookie(matrixSessionCookieName); err != nil || cookie.Value != fakeMatrixSessionID {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if form["activateMenuItem"] == "mss_root" && fm.MenuStatus != 0 {
+			w.WriteHeader(fm.MenuStatus)
+			return
+		}
+
+		page := "/mainMenu.jsf"
+		content := ""
+		switch form["activateMenuItem"] {
+		case "tim_searchWebBookingMss":
+			page = "/bookings.jsf"
+			content = fm.bookingTable()
+		case "tim_persMonthlyReconciliation":
+			page = "/monthly.jsf"
+			content = fm.MonthData
+		case "tim_webTerminalMss":
+			page = "/terminal.jsf"
+			content = fakeMatrixTerminal
+		case "tim_requestBookingCorrectionMss":
+			page = "/correction.jsf"
+			content = fakeMatrixCorrectionForm
+		case "wfl_myRequestsMss":
+			page = "/requests.jsf"
+			content = fm.requestTable()
+		default:
+			if _, ok := form["mainbody:editWebBooking:search"]; ok {
+				page = "/bookings.jsf"
+				content = fm.bookingTableFor(fm.History[form["mainbody:editWebBooking:date_input"]])
+			}
+			if _, ok := form["mainbody:correction:submit"]; ok {
+				page = "/correction.jsf"
+				fm.Corrections = append(fm.Corrections, form)
+				content = `<div class="ui-messages-info"><ul><li>Request submitted</li></ul></div>`
+			}
+			for button, label := range fakeMatrixTerminalButtons {
+				if _, ok := form[button]; ok {
+					page = "/terminal.jsf"
+					if len(fm.BookingError) > 0 {
+						content = `<div class="ui-messages-error"><ul><li>` + fm.BookingError + `</li></ul></div>`
+					} else {
+						content = `<div class="ui-messages-info"><ul><li>Booking successful</li></ul></div>`
+						fm.Bookings += fakeMatrixBookingRows(label, time.Now().Format("15:04"))
+					}
+				}
+			}
+			if fm.OnPost != nil {
+				if c, ok := fm.OnPost(form); ok {
+					page = "/result.jsf"
+					content = c
+				}
+			}
+		}
+		fm.pages[fakeMatrixBaseURL+page] = content
+		w.Header().Set("Location", fakeMatrixBaseURL+page)
+		w.WriteHeader(http.StatusFound)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		content, ok := fm.pages[r.URL.Path]
+		if !ok && r.URL.Path != fakeMatrixBaseURL+"/mainMenu.jsf" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fm.tokenSeq++
+		fmt.Fprintf(w, `<!DOCTYPE html><html lang="en"><head><link rel="stylesheet" href="theme.css?v=4.4.2"></head><body>
+<form id="menuform">
+<input type="hidden" name="uniqueToken" value="token-%d">
+<input type="hidden" name="javax.faces.ViewState" id="j_id1:javax.faces.ViewState:0" value="view-%d">
+<a onclick="PrimeFaces.ab({'activateMenuItem':'tim_searchWebBookingMss','menuform:mainMenu_mss_root_menuid':'17'})">Bookings</a>
+<a onclick="PrimeFaces.ab({'activateMenuItem':'tim_persMonthlyReconciliation','menuform:mainMenu_mss_root_menuid':'23'})">Monthly</a>
+<a onclick="PrimeFaces.ab({'activateMenuItem':'tim_webTerminalMss','menuform:mainMenu_mss_root_menuid':'42'})">Web terminal</a>
+<a onclick="PrimeFaces.ab({'activateMenuItem':'tim_requestBookingCorrectionMss','menuform:mainMenu_mss_root_menuid':'51'})">Correction</a>
+<a onclick="PrimeFaces.ab({'activateMenuItem':'wfl_myRequestsMss','menuform:mainMenu_mss_root_menuid':'52'})">My requests</a>
+</form>
+%s
+</body></html>`, fm.tokenSeq, fm.tokenSeq, content)
+		return
+	}
+
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+func (fm *fakeMatrix) bookingTable() string {
+	return fm.bookingTableFor(fm.Bookings)
+}
+
+func (fm *fakeMatrix) bookingTableFor(rows string) string {
+	return `<form id="mainbody:editWebBooking" method="post">
+<label for="mainbody:editWebBooking:date">Date</label>
+<span id="mainbody:editWebBooking:date"><input name="mainbody:editWebBooking:date_input" placeholder="dd.MM.yyyy"></span>
+<button name="mainbody:editWebBooking:search" type="submit">Search</button>
+</form>
+<table><thead><tr><th>Time</th><th>Booking type</th></tr></thead>
+<tbody id="mainbody:editWebBooking:logTable_data">` + rows + `</tbody></table>`
+}
+
+const fakeMatrixTerminal = `<form id="mainbody:terminal" action="` + fakeMatrixBaseURL + `/terminal.jsf" method="post">
+<input type="hidden" name="mainbody:terminal:mode" value="web">
+<button name="mainbody:terminal:come" type="submit"><span class="ui-button-text">Arrive</span></button>
+<button name="mainbody:terminal:leave" type="submit"><span class="ui-button-text">Leave</span></button>
+<button name="mainbody:terminal:trip" type="submit"><span class="ui-button-text">Business trip</span></button>
+</form>`
+
+const fakeMatrixCorrectionForm = `<form id="mainbody:correction" method="post">
+<label for="mainbody:correction:date">Date</label>
+<span id="mainbody:correction:date"><input name="mainbody:correction:date_input" placeholder="dd.MM.yyyy"></span>
+<label for="mainbody:correction:come">Come:</label><input id="mainbody:correction:come" name="mainbody:correction:come">
+<label for="mainbody:correction:leave">Leave:</label><input id="mainbody:correction:leave" name="mainbody:correction:leave">
+<label for="mainbody:correction:reason">Reason</label><textarea id="mainbody:correction:reason" name="mainbody:correction:reason"></textarea>
+<button name="mainbody:correction:cancel" type="submit">Cancel</button>
+<button name="mainbody:correction:submit" type="submit">Submit</button>
+</form>`
+
+func (fm *fakeMatrix) requestTable() string {
+	var sb strings.Builder
+	sb.WriteString(`<table><thead><tr><th>Date</th><th>Request</th><th>Status</th></tr></thead><tbody>`)
+	sb.WriteString(`<tr><td>01.10.2026</td><td>Booking correction</td><td>Approved</td></tr>`)
+	for _, c := range fm.Corrections {
+		fmt.Fprintf(&sb, `<tr><td>%s</td><td>Booking correction</td><td>Pending</td></tr>`, c["mainbody:correction:date_input"])
+	}
+	sb.WriteString(`</tbody></table>`)
+	return sb.String()
+}
+
+// fakeMatrixBookingRows returns table rows for pairs of booking type and time.
+func fakeMatrixBookingRows(typeAndTime ...string) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(typeAndTime); i += 2 {
+		fmt.Fprintf(&sb, `<tr data-ri="%d"><td>%s</td><td>%s</td></tr>`, i/2, typeAndTime[i+1], typeAndTime[i])
+	}
+	return sb.String()
+}
@@ -6,16 +6,10 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/sbreitf1/gohome/internal/pkg/stdio"
-
-	"github.com/beevik/etree"
 )
 
 const (
@@ -23,14 +17,20 @@ const (
 	matrixRendermapTokenCookieName = "oam.Flash.RENDERMAP.TOKEN"
 	urlMatrixLogin                 = "/login.jspx"
 	urlMatrixMainMenu              = "/mainMenu.jsf"
+	// matrixRequestTimeout keeps the status bar and daemon from hanging on an unreachable server.
+	matrixRequestTimeout = 30 * time.Second
 )
 
 var (
 	matrixVersionURL = "/matrix"
 
-	matrixDebugPrint    = false
+	// matrixLog logs requests and session details of the Matrix client.
+	matrixLog = stdio.Logger("matrix")
+
 	matrixOutputFiles   = false
 	matrixOutputFileDir = ""
+	// matrixRedactTerms are additional values like the full name that are removed from dumped files.
+	matrixRedactTerms []string
 )
 
 // FetchMatrixEntries returns today's entries available in "Aktuelle Buchungen" in Matrix and the current flexitime balance.
@@ -41,13 +41,13 @@ func FetchMatrixEntries(config MatrixConfig) ([]Entry, time.Duration, error) {
 	}
 	defer client.Close()
 
-	stdio.Debug("get entries")
+	matrixLog.Debug("get entries")
 	entries, err := client.GetEntries()
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to retrieve entries: %s", err.Error())
 	}
 
-	stdio.Debug("get flexi time")
+	matrixLog.Debug("get flexi time")
 	flexitime, err := client.GetFlexiTime()
 	if err != nil {
 		return nil, 0, fmt.Errorf("could not retrieve flexitime: %s", err.Error())
@@ -69,18 +69,36 @@ type MatrixClient struct {
 	httpClient      *http.Client
 	sessionID       string
 	rendermapToken  string
-	monthDataID     string
-	bookingID       string
+	menuIDs         map[string]string
 	lastVisitedPage string
 	nextUniqueToken string
 	nextViewState   string
+	serverVersion   string
+	lastPage        *matrixPage
+	dump            *matrixDump
 }
 
 // NewDormaClient returns a logged in DormaClient.
 func NewMatrixClient(config MatrixConfig) (*MatrixClient, error) {
+	client := newMatrixClient(config)
+
+	matrixLog.Debug("logging in", "host", config.Host)
+	if err := client.login(); err != nil {
+		return nil, fmt.Errorf("login failed: %s", err.Error())
+	}
+	matrixLog.Debug("visit self service page")
+	if err := client.visitSelfService(); err != nil {
+		return nil, fmt.Errorf("visit self-service failed: %s", err.Error())
+	}
+	return client, nil
+}
+
+func newMatrixClient(config MatrixConfig) *MatrixClient {
 	client := &MatrixClient{
-		config: config,
+		config:  config,
+		menuIDs: make(map[string]string),
 		httpClient: &http.Client{
+			Timeout: matrixRequestTimeout,
 			Transport: &http.Transport{
 				TLSClientConfig: &tls.Config{
 					InsecureSkipVerify: true,
@@ -89,16 +107,16 @@ func NewMatrixClient(config MatrixConfig) (*MatrixClient, error) {
 			CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
 		},
 	}
-
-	stdio.Debug("logging in")
-	if err := client.login(); err != nil {
-		return nil, fmt.Errorf("login failed: %s", err.Error())
-	}
-	stdio.Debug("visit self service page")
-	if err := client.visitSelfService(); err != nil {
-		return nil, fmt.Errorf("visit self-service failed: %s", err.Error())
+	if matrixOutputFiles {
+		client.dump = newMatrixDump(matrixOutputFileDir, matrixRedactTerms...)
+		client.dump.AddSecrets(config.User, config.Pass)
 	}
-	return client, nil
+	return client
+}
+
+// ServerVersion returns the Matrix version detected during login or an empty string if unknown.
+func (c *MatrixClient) ServerVersion() string {
+	return c.serverVersion
 }
 
 // Close logs out from Matrix and closes the connection.
@@ -119,17 +137,22 @@ func (c *MatrixClient) login() error {
 		return err
 	}
 
-	if _, err := c.postRedirect(matrixVersionURL+urlMatrixLogin, requestBody); err != nil {
+	page, err := c.postRedirect(matrixVersionURL+urlMatrixLogin, requestBody)
+	if err != nil {
 		return err
 	}
-	return nil
+	return c.dump.Page("login", page)
 }
 
 func (c *MatrixClient) detectRedirectURI() error {
-	resp, err := c.httpClient.Get(c.absoluteURL(matrixVersionURL + urlMatrixLogin))
+	loginURL := c.absoluteURL(matrixVersionURL + urlMatrixLogin)
+	resp, err := c.httpClient.Get(loginURL)
 	if err != nil {
+		c.dump.Request(http.MethodGet, loginURL, "", 0, "", err)
 		return err
 	}
+	resp.Body.Close()
+	c.dump.Request(http.MethodGet, loginURL, "", resp.StatusCode, resp.Header.Get("Location"), nil)
 
 	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
 		parts := strings.Split(resp.Header.Get("Location"), "/")
@@ -137,7 +160,12 @@ func (c *MatrixClient) detectRedirectURI() error {
 			return fmt.Errorf("unexpected redirect url for login: %s", resp.Header.Get("Location"))
 		}
 		matrixVersionURL = "/" + parts[1]
-		stdio.Debug("detected matrix url %q", matrixVersionURL)
+		matrixLog.Debug("detected matrix url", "url", matrixVersionURL)
+	}
+
+	if version, ok := parseMatrixVersion(matrixVersionURL); ok {
+		c.serverVersion = version
+		matrixLog.Debug("detected matrix version", "version", c.serverVersion)
 	}
 
 	return nil
@@ -146,10 +174,11 @@ func (c *MatrixClient) detectRedirectURI() error {
 func (c *MatrixClient) visitSelfService() error {
 	requestBody := "uniqueToken=" + c.nextUniqueToken + "&autoScroll=&agmenuform_SUBMIT=1&javax.faces.ViewState=" + c.nextViewState + "&activateMenuItem=mss_root&menuIndex=4&agmenuform%3AassemblyGroupMenu=agmenuform%3AassemblyGroupMenu&data-matrix-treepath=mss_root&agmenuform%3AassemblyGroupMenu_menuid=_c3d3c76c-a976-4d74-a147-db02d56ddb08|4"
 
-	if _, err := c.postRedirect(matrixVersionURL+urlMatrixMainMenu, requestBody); err != nil {
-		return nil
+	page, err := c.postRedirect(matrixVersionURL+urlMatrixMainMenu, requestBody)
+	if err != nil {
+		return err
 	}
-	return nil
+	return c.dump.Page("selfservice", page)
 }
 
 func (c *MatrixClient) logout() error {
@@ -158,169 +187,82 @@ func (c *MatrixClient) logout() error {
 
 // GetEntries returns all entries for the current day.
 func (c *MatrixClient) GetEntries() ([]Entry, error) {
-	requestBody := "uniqueToken=" + c.nextUniqueToken + "&menuform_SUBMIT=1&autoScroll=&javax.faces.ViewState=" + c.nextViewState + "&activateMenuItem=tim_searchWebBookingMss&menuform%3AmainMenu_mss_root_menuid=" + c.bookingID + "&data-matrix-treepath=mss_root.tim_searchWebBookingMss&menuform%3AmainMenu_mss_root=menuform%3AmainMenu_mss_root"
-
-	body, err := c.postRedirect(c.lastVisitedPage, requestBody)
+	page, err := c.visitBookings()
 	if err != nil {
 		return nil, err
 	}
-	if matrixOutputFiles {
-		if err := os.WriteFile(filepath.Join(matrixOutputFileDir, "entries.html"), []byte(body), os.ModePerm); err != nil {
-			return nil, fmt.Errorf("output entries file: %s", err.Error())
-		}
-	}
+	return page.BookingEntries(time.Now())
+}
 
-	doc := etree.NewDocument()
-	if err := doc.ReadFromString(body); err != nil {
-		return nil, fmt.Errorf("read xml string element: %s", err.Error())
+func (c *MatrixClient) visitBookings() (*matrixPage, error) {
+	page, err := c.visitMenuItem(matrixMenuItemBookings)
+	if err != nil {
+		return nil, err
 	}
-
-	tableData := doc.FindElement("//*[@id='mainbody:editWebBooking:logTable_data']")
-	tableRows := tableData.FindElements("//tr[@data-ri]")
-
-	today := time.Now()
-
-	timeStampRegex := regexp.MustCompile(`\s*(\d+):(\d+)\s*`)
-
-	entries := make([]Entry, 0)
-	for _, row := range tableRows {
-		timeSpan := row.ChildElements()[0].ChildElements()[0]
-		if timeSpan == nil {
-			return nil, fmt.Errorf("could not find time span")
-		}
-
-		timeStamp := timeSpan.Text()
-		m := timeStampRegex.FindStringSubmatch(timeStamp)
-
-		hour, _ := strconv.Atoi(m[1])
-		minute, _ := strconv.Atoi(m[2])
-		date := time.Date(today.Year(), today.Month(), today.Day(), hour, minute, 0, 0, time.Local)
-
-		if hour == 0 && minute == 0 {
-			stdio.Debug("ignore booking %q at 00:00", m[4])
-			continue
-		}
-
-		typeStr := row.ChildElements()[1].ChildElements()[0].Text()
-		typeStr = strings.ToLower(typeStr)
-		var entryType EntryType
-		if strings.Contains(typeStr, "kommen") ||
-			strings.Contains(typeStr, "arrive") ||
-			strings.Contains(typeStr, "business authorisation") {
-			entryType = EntryTypeCome
-		} else if strings.Contains(typeStr, "gehen") ||
-			strings.Contains(typeStr, "leave") ||
-			strings.Contains(typeStr, "hourly absence - end") ||
-			strings.Contains(typeStr, "hourly absence end") ||
-			strings.Contains(typeStr, "system - baend") {
-			if strings.Contains(typeStr, "sequence error") {
-				continue
-			}
-			entryType = EntryTypeLeave
-		} else if strings.Contains(typeStr, "???bookingtype.1034.name???") {
-			// "???BookingType.1034.name???" wird geschrieben, wenn man am Terminal den Kontostand abfragt
-			stdio.Debug("found strange booking type: %q", typeStr)
-			continue
-		} else if strings.Contains(typeStr, "valid until") {
-			stdio.Debug("found strange booking type: %q", typeStr)
-			continue
-		} else {
-			return nil, fmt.Errorf("cannot parse entry type from %q", typeStr)
-		}
-
-		entries = append(entries, Entry{Time: date, Type: entryType})
+	if err := c.dump.Page("entries", page); err != nil {
+		return nil, err
 	}
-
-	return entries, nil
+	return page, nil
 }
 
 // GetFlexiTime returns the current flexi time balance.
 func (c *MatrixClient) GetFlexiTime() (time.Duration, error) {
-	requestBody := "uniqueToken=" + c.nextUniqueToken + "&menuform_SUBMIT=1&autoScroll=&javax.faces.ViewState=" + c.nextViewState + "&activateMenuItem=tim_persMonthlyReconciliation&menuform%3AmainMenu_mss_root_menuid=" + c.monthDataID + "&data-matrix-treepath=mss_root.tim_persMonthlyReconciliation&menuform%3AmainMenu_mss_root=menuform%3AmainMenu_mss_root"
-
-	body, err := c.postRedirect(c.lastVisitedPage, requestBody)
+	page, err := c.visitMonthlyReconciliation()
 	if err != nil {
 		return 0, err
 	}
-	if matrixOutputFiles {
-		if err := os.WriteFile(filepath.Join(matrixOutputFileDir, "flexitime.html"), []byte(body), os.ModePerm); err != nil {
-			return 0, fmt.Errorf("output flexitime file: %s", err.Error())
-		}
-	}
-
-	return c.parseFlexiTime(body)
+	return page.FlexiTimeBalance()
 }
 
-func (c *MatrixClient) parseFlexiTime(body string) (time.Duration, error) {
-	doc := etree.NewDocument()
-	err := doc.ReadFromString(body)
+func (c *MatrixClient) visitMonthlyReconciliation() (*matrixPage, error) {
+	page, err := c.visitMenuItem(matrixMenuItemMonthData)
 	if err != nil {
-		return 0, fmt.Errorf("unable to flexi-time balance html")
-	}
-
-	tableData := doc.FindElements("//*[@title='Balance previous day']")
-	if len(tableData) == 0 {
-		tableDataDE := doc.FindElements("//*[@title='Saldo Vortag']")
-		if len(tableDataDE) == 0 {
-			return 0, fmt.Errorf("unable to parse current flexi-time balance")
-		}
-		tableData = tableDataDE
-	}
-
-	lastElement := tableData[len(tableData)-1]
-	if len(lastElement.ChildElements()) == 0 {
-		return 0, fmt.Errorf("unable to find flexi-time balance in element childs")
-	}
-
-	child := lastElement.ChildElements()[0]
-	text := child.Text()
-
-	isNegative := false
-	if strings.Contains(text, "-") {
-		isNegative = true
-		text = strings.ReplaceAll(text, "-", "")
+		return nil, err
 	}
-
-	splitString := strings.Split(text, ":")
-	if len(splitString) != 2 {
-		return 0, fmt.Errorf("unexpected time format found in flexi-time balance")
+	if err := c.dump.Page("flexitime", page); err != nil {
+		return nil, err
 	}
+	return page, nil
+}
 
-	hours, _ := strconv.Atoi(splitString[0])
-	minutes, _ := strconv.Atoi(splitString[1])
-
-	if isNegative {
-		return -1 * (time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute), nil
-	}
+// visitMenuItem opens a page from the self-service menu.
+func (c *MatrixClient) visitMenuItem(item string) (*matrixPage, error) {
+	requestBody := "uniqueToken=" + c.nextUniqueToken + "&menuform_SUBMIT=1&autoScroll=&javax.faces.ViewState=" + c.nextViewState + "&activateMenuItem=" + item + "&menuform%3AmainMenu_mss_root_menuid=" + c.menuIDs[item] + "&data-matrix-treepath=mss_root." + item + "&menuform%3AmainMenu_mss_root=menuform%3AmainMenu_mss_root"
+	return c.postRedirect(c.lastVisitedPage, requestBody)
+}
 
-	return (time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute), nil
+// HasMenuItem returns true if the given item has been found in the self-service menu.
+func (c *MatrixClient) HasMenuItem(item string) bool {
+	return len(c.menuIDs[item]) > 0
 }
 
 func (c *MatrixClient) absoluteURL(url string) string {
 	return strings.TrimRight(c.config.Host, "/") + "/" + strings.TrimLeft(url, "/")
 }
 
-func (c *MatrixClient) postRedirect(url, body string) (string, error) {
+func (c *MatrixClient) postRedirect(url, body string) (*matrixPage, error) {
 	firstURL := c.absoluteURL(url)
 	request, err := http.NewRequest(http.MethodPost, firstURL, strings.NewReader(body))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	c.setCookies(request)
 	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	response, err := c.httpClient.Do(request)
 	if err != nil {
-		return "", err
+		c.dump.Request(http.MethodPost, firstURL, body, 0, "", err)
+		return nil, err
 	}
+	response.Body.Close()
+	c.evalCookies(response)
+	c.dump.Request(http.MethodPost, firstURL, body, response.StatusCode, response.Header.Get("Location"), nil)
 	if (response.StatusCode < 300) || (399 < response.StatusCode) {
-		return "", fmt.Errorf("server returned code %d when 3xx was expected", response.StatusCode)
+		return nil, fmt.Errorf("server returned code %d when 3xx was expected", response.StatusCode)
 	}
 
-	c.evalCookies(response)
-
 	if len(c.sessionID) == 0 {
-		return "", fmt.Errorf("missing Cookie " + matrixSessionCookieName)
+		return nil, fmt.Errorf("missing Cookie " + matrixSessionCookieName)
 	}
 
 	if response.Header.Get("Location") == "favoritePage.jsf" {
@@ -332,72 +274,68 @@ func (c *MatrixClient) postRedirect(url, body string) (string, error) {
 		response.Header.Set("Location", matrixVersionURL+urlMatrixMainMenu)
 	}
 
-	request, err = http.NewRequest(http.MethodGet, c.absoluteURL(response.Header.Get("Location")), nil)
+	secondURL := c.absoluteURL(response.Header.Get("Location"))
+	request, err = http.NewRequest(http.MethodGet, secondURL, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	c.setCookies(request)
 
 	c.lastVisitedPage = response.Header.Get("Location")
-	if matrixDebugPrint {
-		fmt.Println("lastVisitedPage:", c.lastVisitedPage)
-	}
+	matrixLog.Debug("follow redirect", "page", c.lastVisitedPage)
 
 	response, err = c.httpClient.Do(request)
 	if err != nil {
-		return "", err
+		c.dump.Request(http.MethodGet, secondURL, "", 0, "", err)
+		return nil, err
 	}
+	defer response.Body.Close()
+	c.evalCookies(response)
+	c.dump.Request(http.MethodGet, secondURL, "", response.StatusCode, "", nil)
 	if response.StatusCode != 200 {
-		return "", fmt.Errorf("server returned code %d when 200 was expected", response.StatusCode)
+		return nil, fmt.Errorf("server returned code %d when 200 was expected", response.StatusCode)
 	}
 
 	buffer, err := io.ReadAll(response.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	body = string(buffer)
 
-	c.evalCookies(response)
-
-	pattern := regexp.MustCompile(`<input type="hidden" name="uniqueToken" value="([^"]*)" />`)
-	m := pattern.FindStringSubmatch(body)
-	if len(m) != 2 {
-		return "", fmt.Errorf("unable to parse unique token")
-	}
-	c.nextUniqueToken = m[1]
-	if matrixDebugPrint {
-		fmt.Println("UniqueToken:", c.nextUniqueToken)
+	page, err := parseMatrixPage(string(buffer))
+	if err != nil {
+		return nil, err
 	}
+	c.lastPage = page
 
-	pattern = regexp.MustCompile(`javax.faces.ViewState:\d+" value="([^"]*)"`)
-	m = pattern.FindStringSubmatch(body)
-	if len(m) != 2 {
-		return "", fmt.Errorf("unable to parse view state")
+	if len(c.serverVersion) == 0 {
+		if version, ok := page.Version(); ok {
+			c.serverVersion = version
+			matrixLog.Debug("detected matrix version", "version", c.serverVersion)
+		}
 	}
-	c.nextViewState = m[1]
-	if matrixDebugPrint {
-		fmt.Println("ViewState:", c.nextViewState)
+
+	uniqueToken, ok := page.UniqueToken()
+	if !ok {
+		return nil, fmt.Errorf("unable to parse unique token")
 	}
+	c.nextUniqueToken = uniqueToken
+	matrixLog.Debug("parsed unique token", "token", maskSecret(c.nextUniqueToken))
 
-	pattern = regexp.MustCompile(`'tim_searchWebBookingMss','menuform:mainMenu_mss_root_menuid':'(\d+)'`)
-	m = pattern.FindStringSubmatch(body)
-	if len(m) == 2 {
-		c.bookingID = m[1]
-		if matrixDebugPrint {
-			fmt.Println("BookingID:", c.bookingID)
-		}
+	viewState, ok := page.ViewState()
+	if !ok {
+		return nil, fmt.Errorf("unable to parse view state")
 	}
+	c.nextViewState = viewState
+	matrixLog.Debug("parsed view state", "viewState", maskSecret(c.nextViewState))
 
-	pattern = regexp.MustCompile(`'tim_persMonthlyReconciliation','menuform:mainMenu_mss_root_menuid':'(\d+)'`)
-	m = pattern.FindStringSubmatch(body)
-	if len(m) == 2 {
-		c.monthDataID = m[1]
-		if matrixDebugPrint {
-			fmt.Println("MonthDataID:", c.monthDataID)
+	for _, item := range matrixMenuItems {
+		if id, ok := page.MenuItemID(item); ok {
+			c.menuIDs[item] = id
+			matrixLog.Debug("parsed menu item", "item", item, "id", id)
 		}
 	}
 
-	return body, nil
+	return page, nil
 }
 
 func (c *MatrixClient) setCookies(request *http.Request) {
@@ -415,15 +353,13 @@ func (c *MatrixClient) setCookies(request *http.Request) {
 func (c *MatrixClient) evalCookies(response *http.Response) {
 	for _, cookie := range response.Cookies() {
 		if cookie.Name == matrixSessionCookieName {
-			if matrixDebugPrint {
-				fmt.Println("SessionID:", cookie.Value)
-			}
+			matrixLog.Debug("received session id", "sessionID", maskSecret(cookie.Value))
+			c.dump.AddSecrets(cookie.Value)
 			c.sessionID = cookie.Value
 		}
 		if cookie.Name == matrixRendermapTokenCookieName {
-			if matrixDebugPrint {
-				fmt.Println("RendermapToken:", cookie.Value)
-			}
+			matrixLog.Debug("received rendermap token", "token", maskSecret(cookie.Value))
+			c.dump.AddSecrets(cookie.Value)
 			c.rendermapToken = cookie.Value
 		}
 	}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	matrixBookingSearchDateLabels   = []string{"Date", "Datum", "From", "Von"}
+	matrixBookingSearchToLabels     = []string{"To", "Bis"}
+	matrixBookingSearchSubmitLabels = []string{"Search", "Suchen", "Anzeigen", "Show"}
+)
+
+// GetEntriesForDay returns the bookings of the given day by searching the booking list.
+func (c *MatrixClient) GetEntriesForDay(day time.Time) ([]Entry, error) {
+	if isToday(day) {
+		return c.GetEntries()
+	}
+
+	page, err := c.visitBookings()
+	if err != nil {
+		return nil, err
+	}
+	form, err := page.FormWithButton(matrixBookingSearchSubmitLabels...)
+	if err != nil {
+		return nil, fmt.Errorf("booking search: %s", err.Error())
+	}
+	locale, _ := page.Locale()
+
+	dateInput, ok := page.InputByLabel(matrixBookingSearchDateLabels...)
+	if !ok {
+		return nil, fmt.Errorf("date field not found in booking search")
+	}
+	form.Values.Set(dateInput.AttrOr("name", ""), day.Format(matrixDateLayout(dateInput.AttrOr("placeholder", ""), locale)))
+	if toInput, ok := page.InputByLabel(matrixBookingSearchToLabels...); ok {
+		form.Values.Set(toInput.AttrOr("name", ""), day.Format(matrixDateLayout(toInput.AttrOr("placeholder", ""), locale)))
+	}
+
+	result, err := c.submitForm(form)
+	if err != nil {
+		return nil, fmt.Errorf("search bookings of %s: %s", day.Format(historyDateLayout), err.Error())
+	}
+	if err := c.dump.Page("entries-"+day.Format(historyDateLayout), result); err != nil {
+		return nil, err
+	}
+	return result.BookingEntries(day)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	matrixMonthlyDateColumns   = []string{"datum", "date", "tag", "day"}
+	matrixMonthlyActualColumns = []string{"ist", "istzeit", "actual", "actual time", "arbeitszeit", "work time"}
+	matrixMonthlyTargetColumns = []string{"soll", "sollzeit", "target", "target time"}
+	matrixMonthlyBreakColumns  = []string{"pause", "break", "pausenzeit", "break time"}
+	matrixMonthlyDiffColumns   = []string{"differenz", "difference", "diff", "tagessaldo", "day balance"}
+
+	matrixMonthlyMonthLabels  = []string{"Month", "Monat", "Period", "Zeitraum", "From", "Von"}
+	matrixMonthlyToLabels     = []string{"To", "Bis"}
+	matrixMonthlySubmitLabels = []string{"Show", "Anzeigen", "Search", "Suchen", "Apply", "Übernehmen"}
+
+	patternMatrixISODate = regexp.MustCompile(`(\d{4})-(\d{2})-(\d{2})`)
+	patternMatrixDEDate  = regexp.MustCompile(`(\d{1,2})\.(\d{1,2})\.(\d{2,4})?`)
+	patternMatrixENDate  = regexp.MustCompile(`(\d{1,2})/(\d{1,2})(?:/(\d{2,4}))?`)
+)
+
+// MatrixDay contains the daily values Matrix lists on the monthly reconciliation page. Optional columns are nil if not shown.
+type MatrixDay struct {
+	Date       time.Time
+	WorkTime   time.Duration
+	BreakTime  *time.Duration
+	TargetTime *time.Duration
+	FlexiTime  *time.Duration
+}
+
+// MonthlyDays returns the daily values of the monthly reconciliation table. Dates without year are assigned to the given month.
+func (p *matrixPage) MonthlyDays(month time.Time) ([]MatrixDay, error) {
+	var table *matrixTable
+	p.doc.Find("table").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		t, err := findMatrixTable(s.Find("tbody"))
+		if err == nil && t.Column(matrixMonthlyDateColumns...) >= 0 && t.Column(matrixMonthlyActualColumns...) >= 0 {
+			table = t
+			return false
+		}
+		return true
+	})
+	if table == nil {
+		return nil, fmt.Errorf("monthly reconciliation table not found")
+	}
+
+	locale, _ := p.Locale()
+	dateCol := table.Column(matrixMonthlyDateColumns...)
+	actualCol := table.Column(matrixMonthlyActualColumns...)
+	optionalCols := []struct {
+		Col   int
+		Value func(*MatrixDay) **time.Duration
+	}{
+		{table.Column(matrixMonthlyBreakColumns...), func(d *MatrixDay) **time.Duration { return &d.BreakTime }},
+		{table.Column(matrixMonthlyTargetColumns...), func(d *MatrixDay) **time.Duration { return &d.TargetTime }},
+		{table.Column(matrixMonthlyDiffColumns...), func(d *MatrixDay) **time.Duration { return &d.FlexiTime }},
+	}
+
+	days := make([]MatrixDay, 0)
+	for i := range table.Rows {
+		dateStr, _ := table.Cell(i, dateCol)
+		date, ok := parseMatrixDayDate(dateStr, month, locale)
+		if !ok {
+			// sum rows and separators
+			continue
+		}
+
+		day := MatrixDay{Date: date}
+		if str, _ := table.Cell(i, actualCol); len(str) > 0 {
+			d, err := parseMatrixDuration(str)
+			if err != nil {
+				return nil, fmt.Errorf("actual time of %s: %s", date.Format(historyDateLayout), err.Error())
+			}
+			day.WorkTime = d
+		}
+		for _, oc := range optionalCols {
+			str, ok := table.Cell(i, oc.Col)
+			if !ok || len(str) == 0 {
+				continue
+			}
+			d, err := parseMatrixDuration(str)
+			if err != nil {
+				return nil, fmt.Errorf("parse %q of %s: %s", table.Columns[oc.Col], date.Format(historyDateLayout), err.Error())
+			}
+			*oc.Value(&day) = &d
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// parseMatrixDayDate parses dates like "Mo 01.10.", "01.10.2026", "10/01" or "2026-10-01".
+func parseMatrixDayDate(str string, month time.Time, locale string) (time.Time, bool) {
+	var year, mon, day string
+	if m := patternMatrixISODate.FindStringSubmatch(str); len(m) == 4 {
+		year, mon, day = m[1], m[2], m[3]
+	} else if m := patternMatrixDEDate.FindStringSubmatch(str); len(m) == 4 {
+		day, mon, year = m[1], m[2], m[3]
+	} else if m := patternMatrixENDate.FindStringSubmatch(str); len(m) == 4 {
+		mon, day, year = m[1], m[2], m[3]
+		if !strings.HasPrefix(locale, "en") {
+			mon, day = day, mon
+		}
+	} else {
+		return time.Time{}, false
+	}
+
+	y := month.Year()
+	if len(year) > 0 {
+		y, _ = strconv.Atoi(year)
+		if y < 100 {
+			y += 2000
+		}
+	}
+	m, _ := strconv.Atoi(mon)
+	d, _ := strconv.Atoi(day)
+	if m < 1 || m > 12 || d < 1 || d > 31 {
+		return time.Time{}, false
+	}
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.Local), true
+}
+
+// GetMonthlyDays returns the daily values of the given month from the monthly reconciliation page.
+func (c *MatrixClient) GetMonthlyDays(month time.Time) ([]MatrixDay, error) {
+	page, err := c.visitMonthlyReconciliation()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if month.Year() != now.Year() || month.Month() != now.Month() {
+		form, err := page.FormWithButton(matrixMonthlySubmitLabels...)
+		if err != nil {
+			return nil, fmt.Errorf("monthly reconciliation: %s", err.Error())
+		}
+		locale, _ := page.Locale()
+
+		first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+		input, ok := page.InputByLabel(matrixMonthlyMonthLabels...)
+		if !ok {
+			return nil, fmt.Errorf("month field not found in monthly reconciliation")
+		}
+		form.Values.Set(input.AttrOr("name", ""), first.Format(matrixDateLayout(input.AttrOr("placeholder", ""), locale)))
+		if toInput, ok := page.InputByLabel(matrixMonthlyToLabels...); ok {
+			last := first.AddDate(0, 1, -1)
+			form.Values.Set(toInput.AttrOr("name", ""), last.Format(matrixDateLayout(toInput.AttrOr("placeholder", ""), locale)))
+		}
+
+		page, err = c.submitForm(form)
+		if err != nil {
+			return nil, fmt.Errorf("show monthly reconciliation of %s: %s", month.Format("2006-01"), err.Error())
+		}
+		if err := c.dump.Page("monthly-"+month.Format("2006-01"), page); err != nil {
+			return nil, err
+		}
+	}
+	return page.MonthlyDays(month)
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	matrixSelectorUniqueToken  = "input[name='uniqueToken']"
+	matrixSelectorViewState    = "input[name='javax.faces.ViewState']"
+	matrixSelectorBookingTable = "[id='mainbody:editWebBooking:logTable_data']"
+	matrixSelectorFlexiTime    = "[title='Balance previous day'], [title='Saldo Vortag']"
+
+	matrixMenuItemBookings     = "tim_searchWebBookingMss"
+	matrixMenuItemMonthData    = "tim_persMonthlyReconciliation"
+	matrixMenuItemTimeAccounts = "tim_persTimeAccounts"
+)
+
+var (
+	// matrixMenuItems are all self-service menu items whose ids are tracked while navigating.
+	matrixMenuItems = []string{matrixMenuItemBookings, matrixMenuItemMonthData, matrixMenuItemTimeAccounts, matrixMenuItemWebTerminal, matrixMenuItemCorrection, matrixMenuItemRequests}
+
+	matrixBookingTimeColumns = []string{"time", "booking time", "uhrzeit", "buchungszeit"}
+	matrixBookingTypeColumns = []string{"booking type", "type", "buchungsart"}
+
+	patternMatrixTime        = regexp.MustCompile(`(\d+):(\d+)`)
+	patternMatrixDuration    = regexp.MustCompile(`^([-+]?)\s*(\d+):(\d{2})\s*(-?)$`)
+	patternMatrixVersion     = regexp.MustCompile(`(\d+\.\d+\.\d+)`)
+	patternMatrixVersionText = regexp.MustCompile(`(?i)version\s*:?\s*v?(\d+\.\d+(?:\.\d+)*)`)
+)
+
+// matrixPage is a tolerantly parsed HTML page returned by Matrix.
+type matrixPage struct {
+	Body string
+	doc  *goquery.Document
+}
+
+func parseMatrixPage(body string) (*matrixPage, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("parse html: %s", err.Error())
+	}
+	return &matrixPage{Body: body, doc: doc}, nil
+}
+
+// UniqueToken returns the JSF unique token required for the next form submission.
+func (p *matrixPage) UniqueToken() (string, bool) {
+	return p.doc.Find(matrixSelectorUniqueToken).First().Attr("value")
+}
+
+// ViewState returns the JSF view state required for the next form submission.
+func (p *matrixPage) ViewState() (string, bool) {
+	return p.doc.Find(matrixSelectorViewState).First().Attr("value")
+}
+
+// Count returns the number of elements matching the given selector.
+func (p *matrixPage) Count(selector string) int {
+	return p.doc.Find(selector).Length()
+}
+
+// Version returns the Matrix version as announced in the page markup.
+func (p *matrixPage) Version() (string, bool) {
+	if generator, ok := p.doc.Find("meta[name='generator']").Attr("content"); ok {
+		if m := patternMatrixVersionText.FindStringSubmatch("version " + generator); len(m) == 2 {
+			return m[1], true
+		}
+	}
+
+	// resource urls are versioned like "theme.css?v=4.4.2"
+	var version string
+	p.doc.Find("link[href*='v='], script[src*='v=']").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		ref := s.AttrOr("href", s.AttrOr("src", ""))
+		if index := strings.Index(ref, "v="); index >= 0 {
+			if m := patternMatrixVersion.FindStringSubmatch(ref[index:]); len(m) == 2 {
+				version = m[1]
+				return false
+			}
+		}
+		return true
+	})
+	if len(version) > 0 {
+		return version, true
+	}
+
+	if m := patternMatrixVersionText.FindStringSubmatch(p.doc.Text()); len(m) == 2 {
+		return m[1], true
+	}
+	return "", false
+}
+
+// Locale returns the language of the page, either as declared or guessed from known labels.
+func (p *matrixPage) Locale() (string, bool) {
+	html := p.doc.Find("html")
+	if lang, ok := html.Attr("lang"); ok && len(lang) > 0 {
+		return strings.ToLower(lang), true
+	}
+	if lang, ok := html.Attr("xml:lang"); ok && len(lang) > 0 {
+		return strings.ToLower(lang), true
+	}
+
+	text := p.doc.Text()
+	if strings.Contains(text, "Saldo Vortag") || strings.Contains(text, "Buchungen") || strings.Contains(text, "Abmelden") {
+		return "de", true
+	}
+	if strings.Contains(text, "Balance previous day") || strings.Contains(text, "Bookings") || strings.Contains(text, "Logout") {
+		return "en", true
+	}
+	return "", false
+}
+
+// MenuItemID returns the menu id Matrix assigned to the given self-service menu item.
+func (p *matrixPage) MenuItemID(item string) (string, bool) {
+	// the ids are only present in javascript handlers, so a regex is needed here
+	pattern := regexp.MustCompile(`['"]` + regexp.QuoteMeta(item) + `['"]\s*,\s*['"]menuform:mainMenu_mss_root_menuid['"]\s*:\s*['"](\d+)['"]`)
+
+	var id string
+	p.doc.Find("[onclick], script").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		code, _ := s.Attr("onclick")
+		if goquery.NodeName(s) == "script" {
+			code = s.Text()
+		}
+		if m := pattern.FindStringSubmatch(code); len(m) == 2 {
+			id = m[1]
+			return false
+		}
+		return true
+	})
+	return id, len(id) > 0
+}
+
+// BookingEntries returns the entries listed in the booking table. All entries are dated to the given day.
+func (p *matrixPage) BookingEntries(day time.Time) ([]Entry, error) {
+	table, err := findMatrixTable(p.doc.Find(matrixSelectorBookingTable))
+	if err != nil {
+		return nil, fmt.Errorf("booking table: %s", err.Error())
+	}
+
+	timeCol := table.Column(matrixBookingTimeColumns...)
+	if timeCol < 0 {
+		matrixLog.Debug("no time column found, fall back to first column", "columns", table.Columns)
+		timeCol = 0
+	}
+	typeCol := table.Column(matrixBookingTypeColumns...)
+	if typeCol < 0 {
+		matrixLog.Debug("no booking type column found, fall back to second column", "columns", table.Columns)
+		typeCol = 1
+	}
+
+	entries := make([]Entry, 0)
+	for i := range table.Rows {
+		timeStr, ok := table.Cell(i, timeCol)
+		if !ok {
+			return nil, fmt.Errorf("missing time cell in row %d", i)
+		}
+		typeStr, ok := table.Cell(i, typeCol)
+		if !ok {
+			return nil, fmt.Errorf("missing booking type cell in row %d", i)
+		}
+
+		m := patternMatrixTime.FindStringSubmatch(timeStr)
+		if len(m) != 3 {
+			return nil, fmt.Errorf("cannot parse time from %q", timeStr)
+		}
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour == 0 && minute == 0 {
+			matrixLog.Debug("ignore booking at 00:00", "type", typeStr)
+			continue
+		}
+
+		entryType, ok, err := parseMatrixEntryType(typeStr)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		date := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local)
+		entries = append(entries, Entry{Time: date, Type: entryType})
+	}
+	return entries, nil
+}
+
+// parseMatrixEntryType returns the entry type for a Matrix booking type. False is returned for bookings that are no actual come or leave entries.
+func parseMatrixEntryType(typeStr string) (EntryType, bool, error) {
+	typeStr = strings.ToLower(typeStr)
+	if strings.Contains(typeStr, "kommen") ||
+		strings.Contains(typeStr, "arrive") ||
+		strings.Contains(typeStr, "business authorisation") {
+		return EntryTypeCome, true, nil
+	} else if strings.Contains(typeStr, "dienstgang") ||
+		strings.Contains(typeStr, "business trip") {
+		return EntryTypeTrip, true, nil
+	} else if strings.Contains(typeStr, "gehen") ||
+		strings.Contains(typeStr, "leave") ||
+		strings.Contains(typeStr, "hourly absence - end") ||
+		strings.Contains(typeStr, "hourly absence end") ||
+		strings.Contains(typeStr, "system - baend") {
+		if strings.Contains(typeStr, "sequence error") {
+			return "", false, nil
+		}
+		return EntryTypeLeave, true, nil
+	} else if strings.Contains(typeStr, "???bookingtype.1034.name???") {
+		// "???BookingType.1034.name???" wird geschrieben, wenn man am Terminal den Kontostand abfragt
+		matrixLog.Debug("found strange booking type", "type", typeStr)
+		return "", false, nil
+	} else if strings.Contains(typeStr, "valid until") {
+		matrixLog.Debug("found strange booking type", "type", typeStr)
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("cannot parse entry type from %q", typeStr)
+}
+
+// FlexiTimeBalance returns the last flexi-time balance found on the monthly reconciliation page.
+func (p *matrixPage) FlexiTimeBalance() (time.Duration, error) {
+	cells := p.doc.Find(matrixSelectorFlexiTime)
+	if cells.Length() == 0 {
+		return 0, fmt.Errorf("unable to parse current flexi-time balance")
+	}
+
+	cell := cells.Last()
+	if children := cell.Children(); children.Length() > 0 {
+		cell = children.First()
+	}
+	return parseMatrixDuration(cell.Text())
+}
+
+// parseMatrixDuration parses durations like "12:34", "+0:30", "-0:30" or "1:05-".
+func parseMatrixDuration(str string) (time.Duration, error) {
+	m := patternMatrixDuration.FindStringSubmatch(strings.TrimSpace(str))
+	if len(m) != 5 {
+		return 0, fmt.Errorf("unexpected time format %q", str)
+	}
+
+	hours, _ := strconv.Atoi(m[2])
+	minutes, _ := strconv.Atoi(m[3])
+	d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	if m[1] == "-" || m[4] == "-" {
+		return -d, nil
+	}
+	return d, nil
+}
+
+// parseMatrixVersion extracts a version like "4.4.2" from a string like the Matrix base url.
+func parseMatrixVersion(str string) (string, bool) {
+	m := patternMatrixVersion.FindStringSubmatch(str)
+	if len(m) != 2 {
+		return "", false
+	}
+	return m[1], true
+}
+
+// matrixTable is a data table whose cells can be addressed by header names.
+type matrixTable struct {
+	Columns []string
+	Rows    []*goquery.Selection
+}
+
+// findMatrixTable reads the table containing the given element, usually the table body.
+func findMatrixTable(sel *goquery.Selection) (*matrixTable, error) {
+	if sel.Length() == 0 {
+		return nil, fmt.Errorf("table not found")
+	}
+	tableSel := sel.First().Closest("table")
+	if tableSel.Length() == 0 {
+		return nil, fmt.Errorf("element is not part of a table")
+	}
+
+	table := &matrixTable{}
+	tableSel.Find("thead th").Each(func(_ int, th *goquery.Selection) {
+		title := th.Find(".ui-column-title")
+		if title.Length() == 0 {
+			title = th
+		}
+		table.Columns = append(table.Columns, normalizeMatrixText(title.Text()))
+	})
+	tableSel.Find("tbody tr").Each(func(_ int, tr *goquery.Selection) {
+		if tr.HasClass("ui-datatable-empty-message") {
+			return
+		}
+		table.Rows = append(table.Rows, tr)
+	})
+	return table, nil
+}
+
+// Column returns the index of the first column matching one of the given names, or -1.
+func (t *matrixTable) Column(names ...string) int {
+	for _, name := range names {
+		for i, col := range t.Columns {
+			if col == name {
+				return i
+			}
+		}
+	}
+	for _, name := range names {
+		for i, col := range t.Columns {
+			if strings.Contains(col, name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Cell returns the trimmed text of a cell.
+func (t *matrixTable) Cell(row, col int) (string, bool) {
+	if row < 0 || row >= len(t.Rows) || col < 0 {
+		return "", false
+	}
+	cells := t.Rows[row].ChildrenFiltered("td")
+	if col >= cells.Length() {
+		return "", false
+	}
+	// responsive tables repeat the column title in each cell
+	cell := cells.Eq(col).Clone()
+	cell.Find(".ui-column-title").Remove()
+	return strings.TrimSpace(cell.Text()), true
+}
+
+func normalizeMatrixText(str string) string {
+	return strings.ToLower(strings.Join(strings.Fields(str), " "))
+}
+
+// matrixForm contains the values of a JSF form to be submitted by one of its buttons.
+type matrixForm struct {
+	ID     string
+	Action string
+	Values url.Values
+}
+
+// FormWithButton returns the form containing a button with one of the given labels, prepared to be submitted by that button.
+func (p *matrixPage) FormWithButton(labels ...string) (*matrixForm, error) {
+	buttons := p.doc.Find("button[name], input[name][type='submit']")
+	button := findByLabel(buttons, labels, func(s *goquery.Selection) string {
+		if goquery.NodeName(s) == "input" {
+			return s.AttrOr("value", "")
+		}
+		return s.Text()
+	})
+	if button == nil {
+		return nil, fmt.Errorf("no button labeled %q found", labels)
+	}
+
+	formSel := button.Closest("form")
+	if formSel.Length() == 0 {
+		return nil, fmt.Errorf("button %q is not part of a form", button.AttrOr("name", ""))
+	}
+	form := readMatrixForm(formSel)
+	form.Values.Set(button.AttrOr("name", ""), button.AttrOr("value", button.AttrOr("name", "")))
+	return form, nil
+}
+
+// FormByID returns the form with the given id.
+func (p *matrixPage) FormByID(id string) (*matrixForm, error) {
+	formSel := p.doc.Find("form[id='" + id + "']")
+	if formSel.Length() == 0 {
+		return nil, fmt.Errorf("form %q not found", id)
+	}
+	return readMatrixForm(formSel.First()), nil
+}
+
+func readMatrixForm(formSel *goquery.Selection) *matrixForm {
+	form := &matrixForm{
+		ID:     formSel.AttrOr("id", ""),
+		Action: formSel.AttrOr("action", ""),
+		Values: make(url.Values),
+	}
+	formSel.Find("input[name]").Each(func(_ int, s *goquery.Selection) {
+		switch strings.ToLower(s.AttrOr("type", "text")) {
+		case "submit", "button", "reset", "image":
+			return
+		case "checkbox", "radio":
+			if _, checked := s.Attr("checked"); !checked {
+				return
+			}
+		}
+		form.Values.Add(s.AttrOr("name", ""), s.AttrOr("value", ""))
+	})
+	formSel.Find("select[name]").Each(func(_ int, s *goquery.Selection) {
+		option := s.Find("option[selected]").First()
+		if option.Length() == 0 {
+			option = s.Find("option").First()
+		}
+		form.Values.Add(s.AttrOr("name", ""), option.AttrOr("value", strings.TrimSpace(option.Text())))
+	})
+	formSel.Find("textarea[name]").Each(func(_ int, s *goquery.Selection) {
+		form.Values.Add(s.AttrOr("name", ""), s.Text())
+	})
+	return form
+}
+
+// findByLabel returns the first element whose label equals one of the given labels, or contains one if no exact match exists.
+func findByLabel(sel *goquery.Selection, labels []string, labelOf func(*goquery.Selection) string) *goquery.Selection {
+	for _, exact := range []bool{true, false} {
+		for _, label := range labels {
+			label = normalizeMatrixText(label)
+			var found *goquery.Selection
+			sel.EachWithBreak(func(_ int, s *goquery.Selection) bool {
+				text := normalizeMatrixText(labelOf(s))
+				if (exact && text == label) || (!exact && len(text) > 0 && strings.Contains(text, label)) {
+					found = s
+					return false
+				}
+				return true
+			})
+			if found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// Messages returns all error and info messages shown on the page.
+func (p *matrixPage) Messages() (errors []string, infos []string) {
+	collect := func(selector string) []string {
+		messages := make([]string, 0)
+		p.doc.Find(selector).Each(func(_ int, s *goquery.Selection) {
+			if text := strings.Join(strings.Fields(s.Text()), " "); len(text) > 0 {
+				messages = append(messages, text)
+			}
+		})
+		return messages
+	}
+	return collect(".ui-messages-error li, .ui-message-error"), collect(".ui-messages-info li, .ui-message-info")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBookingPage = `<!DOCTYPE html>
+<html><head><title>Matrix</title>
+<body>
+<form id=menuform>
+<input value="token123" type="hidden" name="uniqueToken">
+<input type=hidden value="-123:456" id="j_id1:javax.faces.ViewState:0" name="javax.faces.ViewState">
+<a href="#" onclick="PrimeFaces.ab({'activateMenuItem':'tim_searchWebBookingMss','menuform:mainMenu_mss_root_menuid':'17'})">Bookings</a>
+<a href="#" onclick="PrimeFaces.ab({'activateMenuItem':'tim_persMonthlyReconciliation', 'menuform:mainMenu_mss_root_menuid': '23'})">Monthly</a>
+</form>
+<table>
+<thead><tr><th><span class="ui-column-title">Booking type</span><th><span class="ui-column-title">Time</span></tr></thead>
+<tbody id="mainbody:editWebBooking:logTable_data">
+<tr data-ri=0><td><span>Arrive</span><td><span> 08:03 </span>
+<tr data-ri=1><td>???BookingType.1034.name???<td>09:00
+<tr data-ri=2><td>Leave<td>12:01
+<tr data-ri=3><td>Leave (Sequence error)<td>12:02
+<tr data-ri=4><td>Arrive<td>12:40
+<tr data-ri=5><td>Dienstgang<td>15:00
+<tr data-ri=6><td>Arrive<td>00:00
+</tbody>
+</table>
+</body></html>`
+
+func TestMatrixPageTokens(t *testing.T) {
+	page, err := parseMatrixPage(testBookingPage)
+	require.NoError(t, err)
+
+	token, ok := page.UniqueToken()
+	assert.True(t, ok)
+	assert.Equal(t, "token123", token)
+
+	viewState, ok := page.ViewState()
+	assert.True(t, ok)
+	assert.Equal(t, "-123:456", viewState)
+
+	id, ok := page.MenuItemID(matrixMenuItemBookings)
+	assert.True(t, ok)
+	assert.Equal(t, "17", id)
+
+	id, ok = page.MenuItemID(matrixMenuItemMonthData)
+	assert.True(t, ok)
+	assert.Equal(t, "23", id)
+
+	_, ok = page.MenuItemID("unknown")
+	assert.False(t, ok)
+}
+
+func TestMatrixPageBookingEntries(t *testing.T) {
+	page, err := parseMatrixPage(testBookingPage)
+	require.NoError(t, err)
+
+	day := time.Date(2026, time.October, 14, 0, 0, 0, 0, time.Local)
+	entries, err := page.BookingEntries(day)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Type: EntryTypeCome, Time: time.Date(2026, time.October, 14, 8, 3, 0, 0, time.Local)},
+		{Type: EntryTypeLeave, Time: time.Date(2026, time.October, 14, 12, 1, 0, 0, time.Local)},
+		{Type: EntryTypeCome, Time: time.Date(2026, time.October, 14, 12, 40, 0, 0, time.Local)},
+		{Type: EntryTypeTrip, Time: time.Date(2026, time.October, 14, 15, 0, 0, 0, time.Local)},
+	}, entries)
+}
+
+func TestMatrixPageMissingContent(t *testing.T) {
+	page, err := parseMatrixPage(`<html><body><p>session expired`)
+	require.NoError(t, err)
+
+	_, ok := page.UniqueToken()
+	assert.False(t, ok)
+	_, err = page.BookingEntries(time.Now())
+	assert.Error(t, err)
+	_, err = page.FlexiTimeBalance()
+	assert.Error(t, err)
+}
+
+func TestMatrixPageFlexiTimeBalance(t *testing.T) {
+	page, err := parseMatrixPage(`<table><tr><td title="Saldo Vortag"><span>1:30</span><td title="Saldo Vortag"><span>-2:15</span></table>`)
+	require.NoError(t, err)
+
+	balance, err := page.FlexiTimeBalance()
+	require.NoError(t, err)
+	assert.Equal(t, -dur(2, 15), balance)
+}
+
+func TestParseMatrixDuration(t *testing.T) {
+	testCases := map[string]time.Duration{
+		"12:34":  dur(12, 34),
+		" 0:05 ": dur(0, 5),
+		"-1:30":  -dur(1, 30),
+		"1:30-":  -dur(1, 30),
+	}
+	for str, expected := range testCases {
+		d, err := parseMatrixDuration(str)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, d)
+	}
+
+	_, err := parseMatrixDuration("12.34")
+	assert.Error(t, err)
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+// planEntry is an entry of a plan. Simulated entries can be adjusted interactively.
+type planEntry struct {
+	Entry
+	Simulated bool
+}
+
+// parsePlanBookings parses hypothetical bookings like "come 08:00", "leave 17:15", "break 12:00-12:40", "trip 14:00-15:30" or "trip 14:00". Multiple bookings can be separated by comma.
+func parsePlanBookings(args []string, day time.Time) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			fields := strings.Fields(part)
+			if len(fields) == 0 {
+				continue
+			}
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("invalid booking %q, expected type and time like \"leave 17:15\"", strings.TrimSpace(part))
+			}
+
+			times := strings.SplitN(fields[1], "-", 2)
+			start, err := parsePlanTime(times[0], day)
+			if err != nil {
+				return nil, err
+			}
+			var end time.Time
+			if len(times) == 2 {
+				if end, err = parsePlanTime(times[1], day); err != nil {
+					return nil, err
+				}
+				if !end.After(start) {
+					return nil, fmt.Errorf("end of %q is not after start", strings.TrimSpace(part))
+				}
+			}
+
+			switch strings.ToLower(fields[0]) {
+			case "come", "in":
+				entries = append(entries, Entry{Type: EntryTypeCome, Time: start})
+			case "leave", "out":
+				entries = append(entries, Entry{Type: EntryTypeLeave, Time: start})
+			case "break":
+				if end.IsZero() {
+					return nil, fmt.Errorf("break %q needs a range like 12:00-12:30", fields[1])
+				}
+				entries = append(entries, Entry{Type: EntryTypeLeave, Time: start}, Entry{Type: EntryTypeCome, Time: end})
+			case "trip":
+				entries = append(entries, Entry{Type: EntryTypeTrip, Time: start})
+				if !end.IsZero() {
+					entries = append(entries, Entry{Type: EntryTypeCome, Time: end})
+				}
+			default:
+				return nil, fmt.Errorf("unknown booking type %q, expected come, leave, break or trip", fields[0])
+			}
+		}
+	}
+	return entries, nil
+}
+
+func parsePlanTime(str string, day time.Time) (time.Time, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(str))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse time %q: %s", str, err.Error())
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, time.Local), nil
+}
+
+// mergePlanEntries returns actual and simulated entries ordered by time.
+func mergePlanEntries(actual, simulated []Entry) []planEntry {
+	plan := make([]planEntry, 0, len(actual)+len(simulated))
+	for _, e := range actual {
+		plan = append(plan, planEntry{Entry: e})
+	}
+	for _, e := range simulated {
+		plan = append(plan, planEntry{Entry: e, Simulated: true})
+	}
+	sort.SliceStable(plan, func(i, j int) bool { return plan[i].Time.Before(plan[j].Time) })
+	return plan
+}
+
+func planEntriesOnly(plan []planEntry) []Entry {
+	entries := make([]Entry, 0, len(plan))
+	for _, e := range plan {
+		entries = append(entries, e.Entry)
+	}
+	return entries
+}
+
+// planner evaluates a plan against the flexi-time balance and compliance rules.
+type planner struct {
+	FlexiTimeBalance time.Duration
+	TargetTime       time.Duration
+	Compliance       ComplianceConfig
+	History          *History
+}
+
+// Render writes the plan with its resulting times. The simulated entry with index selected is highlighted, use -1 for none.
+func (p *planner) Render(w io.Writer, plan []planEntry, selected int) {
+	simulatedIndex := 0
+	for _, e := range plan {
+		marker := " "
+		if e.Simulated {
+			marker = "*"
+			if simulatedIndex == selected {
+				marker = ">"
+			}
+			simulatedIndex++
+		}
+		switch e.Type {
+		case EntryTypeCome:
+			fmt.Fprintf(w, "%s %s--> %s%s\n", marker, colors.ComeEntry, e.Time.Format("15:04"), colorEnd)
+		case EntryTypeLeave:
+			fmt.Fprintf(w, "%s %s<-- %s%s\n", marker, colors.LeaveEntry, e.Time.Format("15:04"), colorEnd)
+		case EntryTypeTrip:
+			fmt.Fprintf(w, "%s %s<-- %s DG%s\n", marker, colors.TripEntry, e.Time.Format("15:04"), colorEnd)
+		}
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 53))
+
+	entries := planEntriesOnly(plan)
+	gaps := FindEntryGaps(entries)
+	if len(entries) > 0 {
+		// the clock cannot be ticking after a booking in the future
+		if last := entries[len(entries)-1]; last.Type != EntryTypeLeave && last.Time.After(time.Now()) {
+			gaps = append(gaps, EntryGap{Type: EntryTypeLeave, After: last.Time})
+		}
+	}
+	if len(gaps) > 0 {
+		for _, gap := range gaps {
+			writePlanMessage(w, slog.LevelWarn, gap.String())
+		}
+		return
+	}
+	status, err := ComputeStatus(entries, p.FlexiTimeBalance, p.TargetTime, nil)
+	if err != nil {
+		writePlanMessage(w, slog.LevelError, err.Error())
+		return
+	}
+
+	fmt.Fprintf(w, "worktime:            %s%s%s (%s)\n", colors.WorkTime, formatDurationMinutes(status.AccountedWorkTime), colorEnd, formatFlexiTime(status.FlexiTime))
+	fmt.Fprintf(w, "%sbreak:               %s (taken %s)%s\n", colors.BreakEntry, formatDurationMinutes(status.AccountedBreakTime), formatDurationMinutes(status.BreakTime), colorEnd)
+	fmt.Fprintf(w, "flexi-time balance: %s -> %s\n", formatFlexiTime(status.FlexiTimeBalance), formatFlexiTime(status.NewFlexiTimeBalance()))
+	if status.Ticking() {
+		if lt := status.TargetLeaveTime(); !lt.Time.IsZero() {
+			fmt.Fprintf(w, "go home (%s) at %s%s%s\n", formatDurationMinutes(lt.WorkTime), colors.LeaveTime, lt.Time.Format("15:04"), colorEnd)
+		}
+	}
+
+	findings, err := todayComplianceFindings(p.Compliance, p.History, entries)
+	if err != nil {
+		writePlanMessage(w, slog.LevelWarn, err.Error())
+		return
+	}
+	if len(findings) > 0 {
+		fmt.Fprintln(w, strings.Repeat("-", 53))
+	}
+	for _, f := range findings {
+		writePlanMessage(w, complianceSeverityLevels[f.Severity], f.Rule+": "+f.Message)
+	}
+}
+
+// writePlanMessage writes a message with the same prefix as log messages, because the plan is rendered into the interactive view instead of the log.
+func writePlanMessage(w io.Writer, level slog.Level, msg string) {
+	fmt.Fprintf(w, "[%s] %s\n", stdio.LevelName(level), msg)
+}
+
+// adjustPlanEntry moves the simulated entry with the given index by d. The entry keeps its position between neighbouring entries.
+func adjustPlanEntry(plan []planEntry, selected int, d time.Duration) {
+	simulatedIndex := 0
+	for i := range plan {
+		if !plan[i].Simulated {
+			continue
+		}
+		if simulatedIndex == selected {
+			t := plan[i].Time.Add(d)
+			if (i > 0 && !t.After(plan[i-1].Time)) || (i < len(plan)-1 && !t.Before(plan[i+1].Time)) || !isSameDay(t, plan[i].Time) {
+				return
+			}
+			plan[i].Time = t
+			return
+		}
+		simulatedIndex++
+	}
+}
+
+func isSameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
+
+// runInteractivePlan lets the user adjust simulated entries with arrow keys until enter, escape or q is pressed.
+func (p *planner) runInteractivePlan(plan []planEntry, simulatedCount int) error {
+	restore, err := stdio.MakeRaw()
+	if err != nil {
+		return fmt.Errorf("interactive mode: %s", err.Error())
+	}
+	defer restore()
+
+	selected := 0
+	for {
+		var sb strings.Builder
+		p.Render(&sb, plan, selected)
+		sb.WriteString(strings.Repeat("-", 53) + "\n")
+		sb.WriteString("up/down: select  left/right: -/+5 min  ,/.: -/+1 min  q: quit\n")
+		// raw mode does not translate line feeds
+		fmt.Print("\033[H\033[2J" + strings.ReplaceAll(sb.String(), "\n", "\r\n"))
+
+		key, r, err := stdio.ReadKey()
+		if err != nil {
+			return err
+		}
+		switch {
+		case key == stdio.KeyUp:
+			selected = (selected + simulatedCount - 1) % simulatedCount
+		case key == stdio.KeyDown:
+			selected = (selected + 1) % simulatedCount
+		case key == stdio.KeyLeft:
+			adjustPlanEntry(plan, selected, -5*time.Minute)
+		case key == stdio.KeyRight:
+			adjustPlanEntry(plan, selected, 5*time.Minute)
+		case key == stdio.KeyRune && r == ',':
+			adjustPlanEntry(plan, selected, -time.Minute)
+		case key == stdio.KeyRune && r == '.':
+			adjustPlanEntry(plan, selected, time.Minute)
+		case key == stdio.KeyEnter || key == stdio.KeyEscape || key == stdio.KeyRune && r == 'q':
+			return nil
+		}
+	}
+}
+
+func cmdPlan() error {
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		stdio.Warn("read user config failed: %s", err.Error())
+	}
+	targetTime, err := parseTargetTime(cli.Plan.TargetTime, usrConf)
+	if err != nil {
+		return err
+	}
+
+	var actual []Entry
+	var flexiTimeBalance time.Duration
+	if !cli.Plan.Empty {
+		actual, flexiTimeBalance, _, _, err = loadEntries(false, time.Duration(cli.Plan.CacheTimeSeconds)*time.Second)
+		if err != nil {
+			return err
+		}
+	}
+
+	simulated, err := parsePlanBookings(cli.Plan.Bookings, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(simulated) == 0 && cli.Plan.Interactive {
+		return fmt.Errorf("no bookings to adjust")
+	}
+
+	h, err := ReadHistory()
+	if err != nil {
+		return fmt.Errorf("read history: %s", err.Error())
+	}
+	p := &planner{FlexiTimeBalance: flexiTimeBalance, TargetTime: targetTime, Compliance: usrConf.Compliance, History: h}
+	plan := mergePlanEntries(actual, simulated)
+
+	if cli.Plan.Interactive {
+		if err := p.runInteractivePlan(plan, len(simulated)); err != nil {
+			return err
+		}
+		fmt.Print("\033[H\033[2J")
+	}
+	p.Render(os.Stdout, plan, -1)
+	return nil
+}
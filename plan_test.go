@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlanBookings(t *testing.T) {
+	day := time.Date(2026, time.October, 14, 0, 0, 0, 0, time.Local)
+	entries, err := parsePlanBookings([]string{"break 12:00-12:40, leave 17:15", "trip 14:00-15:30", "trip 16:00"}, day)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Type: EntryTypeLeave, Time: day.Add(dur(12, 0))},
+		{Type: EntryTypeCome, Time: day.Add(dur(12, 40))},
+		{Type: EntryTypeLeave, Time: day.Add(dur(17, 15))},
+		{Type: EntryTypeTrip, Time: day.Add(dur(14, 0))},
+		{Type: EntryTypeCome, Time: day.Add(dur(15, 30))},
+		{Type: EntryTypeTrip, Time: day.Add(dur(16, 0))},
+	}, entries)
+
+	for _, invalid := range []string{"break 12:00", "lunch 12:00", "leave", "break 12:40-12:00", "leave 25:00"} {
+		_, err := parsePlanBookings([]string{invalid}, day)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPlannerRender(t *testing.T) {
+	oldColors, oldColorEnd := colors, colorEnd
+	disableColors()
+	defer func() { colors, colorEnd = oldColors, oldColorEnd }()
+
+	day := truncateDay(time.Now())
+	simulated, err := parsePlanBookings([]string{"break 12:00-12:40, leave 17:15"}, day)
+	require.NoError(t, err)
+	plan := mergePlanEntries([]Entry{{Type: EntryTypeCome, Time: day.Add(dur(8, 0))}}, simulated)
+
+	p := &planner{FlexiTimeBalance: time.Hour, TargetTime: 8 * time.Hour, History: &History{Days: make(map[string]HistoryDay)}}
+	var sb strings.Builder
+	p.Render(&sb, plan, 1)
+	assert.Equal(t, `  --> 08:00
+* <-- 12:00
+> --> 12:40
+* <-- 17:15
+-----------------------------------------------------
+worktime:            08:35 (+00:35)
+break:               00:40 (taken 00:40)
+flexi-time balance: +01:00 -> +01:35
+`, sb.String())
+
+	adjustPlanEntry(plan, 2, 5*time.Minute)
+	assert.Equal(t, day.Add(dur(17, 20)), plan[3].Time)
+	adjustPlanEntry(plan, 0, 45*time.Minute)
+	assert.Equal(t, day.Add(dur(12, 0)), plan[1].Time, "entries cannot pass their neighbours")
+
+	plan = mergePlanEntries(planEntriesOnly(plan), []Entry{{Type: EntryTypeCome, Time: day.Add(dur(15, 0))}})
+	sb.Reset()
+	p.Render(&sb, plan, -1)
+	assert.Contains(t, sb.String(), "[WARN] missing leave booking between 12:40 and 15:00")
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+var (
+	// reconcileRules are checked in order to explain a mismatch between gohome and Matrix.
+	reconcileRules = []reconcileRule{
+		{"missing leave booking", func(d ReconcileDay) bool { return d.Local.Incomplete }},
+		{"day not accounted by Matrix (absence or not processed yet)", func(d ReconcileDay) bool { return d.Matrix.WorkTime == 0 }},
+		{"daily target time", func(d ReconcileDay) bool {
+			return d.Diff == 0 && d.Matrix.TargetTime != nil && *d.Matrix.TargetTime != d.TargetTime
+		}},
+		{"10 hour daily maximum", func(d ReconcileDay) bool {
+			return d.Local.AccountedWorkTime >= 10*time.Hour && d.Matrix.WorkTime > 10*time.Hour
+		}},
+		{"mandatory breaks (30 min after 6 hours, 45 min after 9 hours)", func(d ReconcileDay) bool {
+			if d.Matrix.BreakTime != nil && *d.Matrix.BreakTime != noSeconds(d.Local.AccountedBreakTime) {
+				return true
+			}
+			return d.Local.AccountedWorkTime != d.Local.WorkTime && noSeconds(d.Local.WorkTime) == d.Matrix.WorkTime
+		}},
+		{"bookings rounded to 5 minutes", func(d ReconcileDay) bool { return roundedWorkTime(*d.Local, 5*time.Minute) == d.Matrix.WorkTime }},
+		{"bookings rounded to 15 minutes", func(d ReconcileDay) bool { return roundedWorkTime(*d.Local, 15*time.Minute) == d.Matrix.WorkTime }},
+		{"seconds of bookings", func(d ReconcileDay) bool { return d.Diff >= -time.Minute && d.Diff <= time.Minute }},
+		{"business trips", func(d ReconcileDay) bool { return len(d.Local.Trips()) > 0 }},
+	}
+)
+
+type reconcileRule struct {
+	Name    string
+	Applies func(ReconcileDay) bool
+}
+
+// ReconcileDay compares the local accounting of a day with the values of Matrix.
+type ReconcileDay struct {
+	Date time.Time
+	// Local is nil for days without bookings in the history.
+	Local *DaySummary
+	// Matrix is nil for days not listed on the monthly reconciliation page.
+	Matrix     *MatrixDay
+	TargetTime time.Duration
+	// Diff is the local minus the Matrix accounted work time.
+	Diff     time.Duration
+	Mismatch bool
+	// Rule is the accounting rule that most likely caused a mismatch.
+	Rule string
+}
+
+// reconcileDays compares all days between from and to (inclusive) from the history with the daily values of Matrix.
+func reconcileDays(h *History, matrixDays []MatrixDay, from, to time.Time, targetTime time.Duration) []ReconcileDay {
+	byDate := make(map[string]*MatrixDay)
+	for i := range matrixDays {
+		byDate[matrixDays[i].Date.Format(historyDateLayout)] = &matrixDays[i]
+	}
+
+	days := make([]ReconcileDay, 0)
+	for date := truncateDay(from); !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := ReconcileDay{Date: date, Matrix: byDate[date.Format(historyDateLayout)], TargetTime: targetTime}
+		if hd, ok := h.Get(date); ok && len(hd.Entries) > 0 {
+			s, err := SummarizeDay(date, hd.Entries, targetTime)
+			if err != nil {
+				stdio.Warn("skip %s: %s", date.Format(historyDateLayout), err.Error())
+				continue
+			}
+			day.Local = &s
+		}
+		if day.Local == nil && (day.Matrix == nil || day.Matrix.WorkTime == 0) {
+			continue
+		}
+		day.compare()
+		days = append(days, day)
+	}
+	return days
+}
+
+func (d *ReconcileDay) compare() {
+	switch {
+	case d.Local == nil:
+		d.Diff = -d.Matrix.WorkTime
+		d.Mismatch = true
+		d.Rule = "no local bookings"
+		return
+	case d.Matrix == nil:
+		d.Diff = noSeconds(d.Local.AccountedWorkTime)
+		d.Mismatch = true
+		d.Rule = "day missing in Matrix"
+		return
+	}
+
+	d.Diff = noSeconds(d.Local.AccountedWorkTime) - d.Matrix.WorkTime
+	d.Mismatch = d.Diff != 0 || d.Local.Incomplete || d.Matrix.TargetTime != nil && *d.Matrix.TargetTime != d.TargetTime
+	if !d.Mismatch {
+		return
+	}
+	d.Rule = "unknown"
+	for _, r := range reconcileRules {
+		if r.Applies(*d) {
+			d.Rule = r.Name
+			return
+		}
+	}
+}
+
+// roundedWorkTime returns the accounted work time with come bookings rounded up and leave bookings rounded down to the given interval.
+func roundedWorkTime(s DaySummary, interval time.Duration) time.Duration {
+	if len(s.Blocks) == 0 || s.Incomplete {
+		return 0
+	}
+	var workTime time.Duration
+	for _, b := range s.Blocks {
+		start := b.Start.Truncate(interval)
+		if start.Before(b.Start) {
+			start = start.Add(interval)
+		}
+		if end := b.End.Truncate(interval); end.After(start) {
+			workTime += end.Sub(start)
+		}
+	}
+	first := s.Blocks[0].Start.Truncate(interval)
+	if first.Before(s.Blocks[0].Start) {
+		first = first.Add(interval)
+	}
+	presence := s.Blocks[len(s.Blocks)-1].End.Truncate(interval).Sub(first)
+	accountedWorkTime, _, _ := ComputeAccountedWorkTime(workTime, presence-workTime)
+	return noSeconds(accountedWorkTime)
+}
+
+// parseMonth parses a month in format "2006-01", defaulting to the current month.
+func parseMonth(str string) (time.Time, error) {
+	if len(str) == 0 {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local), nil
+	}
+	month, err := time.ParseInLocation("2006-01", str, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse month: %s", err.Error())
+	}
+	return month, nil
+}
+
+func cmdReconcile() error {
+	month, err := parseMonth(cli.Reconcile.Month)
+	if err != nil {
+		return err
+	}
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		stdio.Warn("read user config failed: %s", err.Error())
+	}
+	targetTime, err := parseTargetTime(cli.Reconcile.TargetTime, usrConf)
+	if err != nil {
+		return err
+	}
+
+	// Matrix accounts days after they are over
+	from := month
+	to := month.AddDate(0, 1, -1)
+	if yesterday := truncateDay(time.Now()).AddDate(0, 0, -1); to.After(yesterday) {
+		to = yesterday
+	}
+	if to.Before(from) {
+		return fmt.Errorf("no finished days in %s", month.Format("2006-01"))
+	}
+
+	h, err := ReadHistory()
+	if err != nil {
+		return fmt.Errorf("read history: %s", err.Error())
+	}
+
+	matrixConfig, err := getMatrixConfigWithPassword()
+	if err != nil {
+		return err
+	}
+	client, err := NewMatrixClient(matrixConfig)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	matrixLog.Debug("get monthly reconciliation", "month", month.Format("2006-01"))
+	matrixDays, err := client.GetMonthlyDays(month)
+	if err != nil {
+		return fmt.Errorf("could not retrieve monthly reconciliation: %s", err.Error())
+	}
+	if err := fetchHistoryDates(client, h, missingHistoryDates(h, from, to)); err != nil {
+		return err
+	}
+
+	days := reconcileDays(h, matrixDays, from, to, targetTime)
+	printReconcileDays(days, cli.Reconcile.All)
+	return nil
+}
+
+func printReconcileDays(days []ReconcileDay, all bool) {
+	mismatches := 0
+	stdio.Println("%-10s  %6s  %6s  %6s  %s", "date", "gohome", "Matrix", "diff", "likely cause")
+	for _, d := range days {
+		if d.Mismatch {
+			mismatches++
+		} else if !all {
+			continue
+		}
+
+		local, matrix, diff := "-", "-", ""
+		if d.Local != nil && !d.Local.Incomplete {
+			local = formatDurationMinutes(d.Local.AccountedWorkTime)
+		}
+		if d.Matrix != nil {
+			matrix = formatDurationMinutes(d.Matrix.WorkTime)
+		}
+		if d.Diff != 0 {
+			diff = formatSignedDurationMinutes(d.Diff)
+		}
+		line := fmt.Sprintf("%-10s  %6s  %6s  %6s  %s", d.Date.Format(historyDateLayout), local, matrix, diff, d.Rule)
+		if d.Mismatch {
+			line = colors.FlexiTimeMinus + line + colorEnd
+		}
+		stdio.Println("%s", line)
+	}
+	stdio.Println("%s", "-----------------------------------------------------")
+	stdio.Println("%d of %d days differ", mismatches, len(days))
+}
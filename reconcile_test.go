@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMonthlyPage = `<html lang="de"><body>
+<table><tr><td title="Saldo Vortag"><span>1:30</span></td></tr></table>
+<table>
+<thead><tr><th><span class="ui-column-title">Datum</span></th><th>Soll</th><th>Ist</th><th>Pause</th><th>Differenz</th></tr></thead>
+<tbody>
+<tr><td>Di 01.09.</td><td>8:00</td><td>8:00</td><td>0:30</td><td>0:00</td></tr>
+<tr><td>Mi 02.09.</td><td>8:00</td><td>7:45</td><td>0:45</td><td>0:15-</td></tr>
+<tr><td>Sa 05.09.</td><td></td><td></td><td></td><td></td></tr>
+<tr><td>Summe</td><td>16:00</td><td>15:45</td><td></td><td>0:15-</td></tr>
+</tbody>
+</table>
+</body></html>`
+
+func TestMatrixPageMonthlyDays(t *testing.T) {
+	page, err := parseMatrixPage(testMonthlyPage)
+	require.NoError(t, err)
+
+	month := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.Local)
+	days, err := page.MonthlyDays(month)
+	require.NoError(t, err)
+	require.Len(t, days, 3)
+	assert.Equal(t, month.AddDate(0, 0, 1), days[1].Date)
+	assert.Equal(t, dur(7, 45), days[1].WorkTime)
+	require.NotNil(t, days[1].BreakTime)
+	assert.Equal(t, dur(0, 45), *days[1].BreakTime)
+	require.NotNil(t, days[1].FlexiTime)
+	assert.Equal(t, -dur(0, 15), *days[1].FlexiTime)
+	assert.Nil(t, days[2].TargetTime)
+}
+
+func TestParseMatrixDayDate(t *testing.T) {
+	month := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.Local)
+	for str, expected := range map[string]time.Time{
+		"Mo 07.09.":  month.AddDate(0, 0, 6),
+		"01.10.2026": month.AddDate(0, 1, 0),
+		"09/03":      month.AddDate(0, 0, 2),
+		"2026-09-30": month.AddDate(0, 0, 29),
+	} {
+		date, ok := parseMatrixDayDate(str, month, "en")
+		assert.True(t, ok, str)
+		assert.Equal(t, expected, date, str)
+	}
+	_, ok := parseMatrixDayDate("Total", month, "en")
+	assert.False(t, ok)
+}
+
+func TestReconcileDays(t *testing.T) {
+	from := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.Local)
+	at := func(day, hour, minute int) time.Time {
+		return from.AddDate(0, 0, day-1).Add(dur(hour, minute))
+	}
+	h := &History{Days: make(map[string]HistoryDay)}
+	put := func(entries ...Entry) { h.Put(entries[0].Time, entries, nil) }
+	put(Entry{EntryTypeCome, at(1, 8, 0)}, Entry{EntryTypeLeave, at(1, 16, 30)})
+	put(Entry{EntryTypeCome, at(2, 8, 3)}, Entry{EntryTypeLeave, at(2, 16, 31)})
+	put(Entry{EntryTypeCome, at(3, 8, 0)}, Entry{EntryTypeLeave, at(3, 14, 20)})
+	put(Entry{EntryTypeCome, at(4, 8, 0)})
+	put(Entry{EntryTypeCome, at(7, 8, 0)}, Entry{EntryTypeLeave, at(7, 16, 30)})
+
+	d := func(h, m int) *time.Duration {
+		v := dur(h, m)
+		return &v
+	}
+	matrixDays := []MatrixDay{
+		{Date: at(1, 0, 0), WorkTime: dur(8, 0), TargetTime: d(8, 0)},
+		{Date: at(2, 0, 0), WorkTime: dur(7, 45)},
+		{Date: at(3, 0, 0), WorkTime: dur(6, 20)},
+		{Date: at(4, 0, 0), WorkTime: 0},
+		{Date: at(5, 0, 0), WorkTime: 0},
+		{Date: at(6, 0, 0), WorkTime: dur(8, 0)},
+		{Date: at(7, 0, 0), WorkTime: dur(8, 0), TargetTime: d(7, 0)},
+	}
+
+	days := reconcileDays(h, matrixDays, from, at(7, 0, 0), 8*time.Hour)
+	require.Len(t, days, 6, "days without bookings and accounting are skipped")
+
+	assert.False(t, days[0].Mismatch)
+	assert.Empty(t, days[0].Rule)
+
+	assert.True(t, days[1].Mismatch)
+	assert.Equal(t, dur(0, 13), days[1].Diff)
+	assert.Equal(t, "bookings rounded to 15 minutes", days[1].Rule)
+
+	assert.Equal(t, -dur(0, 20), days[2].Diff)
+	assert.Equal(t, "mandatory breaks (30 min after 6 hours, 45 min after 9 hours)", days[2].Rule)
+
+	assert.Equal(t, "missing leave booking", days[3].Rule)
+
+	assert.Nil(t, days[4].Local)
+	assert.Equal(t, "no local bookings", days[4].Rule)
+
+	assert.True(t, days[5].Mismatch)
+	assert.Equal(t, "daily target time", days[5].Rule)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const redactedValue = "[REDACTED]"
+
+var (
+	// matrixRedactAttrHints mark elements whose text contains personal data like names or personnel numbers.
+	matrixRedactAttrHints = []string{"username", "user-name", "personname", "person-name", "persnr", "personalnummer", "personnel", "employee", "mitarbeiter", "fullname"}
+	// matrixFormSafeKeys are form fields that only contain navigation data.
+	matrixFormSafeKeys = []string{"activateMenuItem", "data-matrix-treepath", "menuIndex", "autoScroll", "systemLevel", "timezonename", "timezoneoffset", "timezonedst", "loginButton"}
+
+	patternInputTag  = regexp.MustCompile(`(?is)<input\b[^>]*>`)
+	patternValueAttr = regexp.MustCompile(`(?is)(\svalue\s*=\s*)("[^"]*"|'[^']*'|[^\s>]+)`)
+	patternInputType = regexp.MustCompile(`(?is)\btype\s*=\s*["']?(submit|button|reset|image)\b`)
+	patternTextarea  = regexp.MustCompile(`(?is)(<textarea\b[^>]*>)(.*?)(</textarea>)`)
+	patternEmail     = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// redactSecrets replaces all occurrences of the given secrets. Longer secrets are replaced first so partial overlaps do not leak.
+func redactSecrets(str string, secrets ...string) string {
+	sorted := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		if len(s) > 0 {
+			sorted = append(sorted, s)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	for _, s := range sorted {
+		str = strings.ReplaceAll(str, s, redactedValue)
+		// secrets also appear url-encoded in links and scripts
+		if encoded := url.QueryEscape(s); encoded != s {
+			str = strings.ReplaceAll(str, encoded, redactedValue)
+		}
+	}
+	return str
+}
+
+// redactMatrixPage removes personal data, form values and the given secrets from a Matrix page while retaining the original markup.
+func redactMatrixPage(body string, secrets ...string) string {
+	secrets = append(secrets, findPersonalData(body)...)
+
+	body = patternInputTag.ReplaceAllStringFunc(body, func(input string) string {
+		if patternInputType.MatchString(input) {
+			return input
+		}
+		return patternValueAttr.ReplaceAllString(input, `${1}"`+redactedValue+`"`)
+	})
+	body = patternTextarea.ReplaceAllString(body, "${1}"+redactedValue+"${3}")
+	body = patternEmail.ReplaceAllString(body, redactedValue)
+	return redactSecrets(body, secrets...)
+}
+
+// findPersonalData returns texts of elements that are marked to contain names or personnel numbers.
+func findPersonalData(body string) []string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	values := make([]string, 0)
+	doc.Find("[id], [class], [title]").Each(func(_ int, s *goquery.Selection) {
+		hint := strings.ToLower(s.AttrOr("id", "") + " " + s.AttrOr("class", "") + " " + s.AttrOr("title", ""))
+		for _, h := range matrixRedactAttrHints {
+			if strings.Contains(hint, h) {
+				if text := strings.TrimSpace(s.Text()); len(text) >= 3 {
+					values = append(values, text)
+				}
+				return
+			}
+		}
+	})
+	return values
+}
+
+// redactMatrixForm returns form values for logging with all values replaced that are not required to retrace navigation.
+func redactMatrixForm(body string) map[string]string {
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		return map[string]string{"": fmt.Sprintf("unparsable form: %s", err.Error())}
+	}
+
+	form := make(map[string]string)
+	for key := range values {
+		value := values.Get(key)
+		if len(value) > 0 && !isMatrixFormSafeKey(key) {
+			value = redactedValue
+		}
+		form[key] = value
+	}
+	return form
+}
+
+func isMatrixFormSafeKey(key string) bool {
+	if strings.HasSuffix(key, "_SUBMIT") || strings.HasSuffix(key, "_menuid") {
+		return true
+	}
+	for _, k := range matrixFormSafeKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// maskSecret replaces a secret value for debug output. Only empty values are shown, because they hint at parsing errors.
+func maskSecret(secret string) string {
+	if len(secret) == 0 {
+		return ""
+	}
+	return redactedValue
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	assert.Equal(t, "user [REDACTED] with [REDACTED]", redactSecrets("user jdoe with p@ss word", "jdoe", "p@ss word", ""))
+	assert.Equal(t, "query=[REDACTED]", redactSecrets("query=p%40ss+word", "p@ss word"))
+	assert.Equal(t, "[REDACTED]", redactSecrets("abcdef", "abc", "abcdef"))
+}
+
+func TestRedactMatrixPage(t *testing.T) {
+	body := `<html><body>
+<span class="userName">Jane Doe</span>
+<table><tr><td title="Personalnummer">004711</td></tr></table>
+<input type="hidden" name="uniqueToken" value="tok123">
+<input value=plain name=other>
+<input name="loginButton" value="Anmeldung" type="submit">
+<textarea name="reason">forgot badge</textarea>
+<p>Contact jane.doe@example.com or Jane Doe</p>
+</body></html>`
+
+	redacted := redactMatrixPage(body, "session-1")
+	for _, secret := range []string{"Jane Doe", "004711", "tok123", "plain", "forgot badge", "jane.doe@example.com"} {
+		assert.NotContains(t, redacted, secret)
+	}
+	assert.Contains(t, redacted, `value="Anmeldung"`)
+	assert.Contains(t, redacted, `<span class="userName">[REDACTED]</span>`)
+}
+
+func TestRedactMatrixForm(t *testing.T) {
+	form := redactMatrixForm("uniqueToken=abc&menuform_SUBMIT=1&activateMenuItem=tim_searchWebBookingMss&password=secret&autoScroll=")
+	assert.Equal(t, map[string]string{
+		"uniqueToken":      redactedValue,
+		"menuform_SUBMIT":  "1",
+		"activateMenuItem": "tim_searchWebBookingMss",
+		"password":         redactedValue,
+		"autoScroll":       "",
+	}, form)
+}
+
+func TestMaskSecret(t *testing.T) {
+	assert.Equal(t, "", maskSecret(""))
+	assert.Equal(t, redactedValue, maskSecret("abc"))
+	assert.Equal(t, redactedValue, maskSecret("abcdefghij"))
+}
+
+func TestMatrixDump(t *testing.T) {
+	fm := newFakeMatrix(t)
+
+	dir := t.TempDir()
+	oldOutputFiles, oldOutputFileDir := matrixOutputFiles, matrixOutputFileDir
+	matrixOutputFiles, matrixOutputFileDir = true, dir
+	defer func() { matrixOutputFiles, matrixOutputFileDir = oldOutputFiles, oldOutputFileDir }()
+
+	_, _, err := FetchMatrixEntries(fm.Config())
+	require.NoError(t, err)
+
+	for _, file := range []string{"login.html", "selfservice.html", "entries.html", "flexitime.html", "manifest.json"} {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		require.NoError(t, err, file)
+		for _, secret := range []string{fakeMatrixUser, fakeMatrixPass, fakeMatrixSessionID, "token-", "view-"} {
+			assert.False(t, strings.Contains(string(data), secret), "%s contains %q", file, secret)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	require.NoError(t, err)
+	var requests []matrixDumpRequest
+	require.NoError(t, json.Unmarshal(data, &requests))
+	require.NotEmpty(t, requests)
+	assert.Equal(t, "GET", requests[0].Method)
+	files := make([]string, 0)
+	for _, r := range requests {
+		if len(r.File) > 0 {
+			files = append(files, r.File)
+		}
+	}
+	assert.Equal(t, []string{"login.html", "selfservice.html", "entries.html", "flexitime.html"}, files)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+const (
+	// ReminderWorkTimeTarget can be used as milestone work time to refer to the current target time.
+	ReminderWorkTimeTarget = "target"
+)
+
+// ReminderConfig configures when and how reminders are sent.
+type ReminderConfig struct {
+	// Scheduler is one of "at", "systemd" or "daemon".
+	Scheduler string `json:"Scheduler,omitempty"`
+	// Notifiers is a list of "notify-send", "dbus", "hook" and "webhook".
+	Notifiers   []string            `json:"Notifiers,omitempty"`
+	HookCommand string              `json:"HookCommand,omitempty"`
+	WebhookURL  string              `json:"WebhookURL,omitempty"`
+	Milestones  []ReminderMilestone `json:"Milestones,omitempty"`
+}
+
+// ReminderMilestone defines a reminder relative to the leave time for a given work time.
+type ReminderMilestone struct {
+	Name string `json:"Name"`
+	// WorkTime is the accounted work time in format "15:04" or "target".
+	WorkTime string `json:"WorkTime"`
+	// LeadTime in format "15:04" is subtracted from the leave time.
+	LeadTime string `json:"LeadTime,omitempty"`
+	Message  string `json:"Message"`
+}
+
+// Reminder is a notification scheduled for a given time.
+type Reminder struct {
+	Milestone string    `json:"milestone"`
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+	Scheduler string    `json:"scheduler"`
+	JobID     string    `json:"job_id,omitempty"`
+	Fired     bool      `json:"fired,omitempty"`
+}
+
+func defaultReminderMilestones() []ReminderMilestone {
+	return []ReminderMilestone{
+		{Name: "gohome", WorkTime: ReminderWorkTimeTarget, Message: "Go home!"},
+		{Name: "10h", WorkTime: "10:00", LeadTime: "00:15", Message: "10h-Limit in 15 min! GO HOME!"},
+	}
+}
+
+// WithDefaults returns a config with the classic behavior of at and notify-send for all unset values.
+func (conf ReminderConfig) WithDefaults() ReminderConfig {
+	if len(conf.Scheduler) == 0 {
+		conf.Scheduler = "at"
+	}
+	if len(conf.Notifiers) == 0 {
+		conf.Notifiers = []string{"notify-send"}
+	}
+	if len(conf.Milestones) == 0 {
+		conf.Milestones = defaultReminderMilestones()
+	}
+	return conf
+}
+
+// ComputeReminders returns a reminder for every milestone.
+func ComputeReminders(milestones []ReminderMilestone, startTime time.Time, breakTime, targetTime time.Duration) ([]Reminder, error) {
+	reminders := make([]Reminder, 0, len(milestones))
+	for _, m := range milestones {
+		workTime := targetTime
+		if m.WorkTime != ReminderWorkTimeTarget {
+			var err error
+			workTime, err = parseDurationHHMM(m.WorkTime)
+			if err != nil {
+				return nil, fmt.Errorf("invalid work time for milestone %q: %s", m.Name, err.Error())
+			}
+		}
+		var leadTime time.Duration
+		if len(m.LeadTime) > 0 {
+			var err error
+			leadTime, err = parseDurationHHMM(m.LeadTime)
+			if err != nil {
+				return nil, fmt.Errorf("invalid lead time for milestone %q: %s", m.Name, err.Error())
+			}
+		}
+
+		leaveTime, err := GetLeaveTime(startTime, breakTime, workTime)
+		if err != nil {
+			return nil, fmt.Errorf("milestone %q: %s", m.Name, err.Error())
+		}
+		reminders = append(reminders, Reminder{Milestone: m.Name, Time: leaveTime.Add(-leadTime), Message: m.Message})
+	}
+	sort.Slice(reminders, func(i, j int) bool { return reminders[i].Time.Before(reminders[j].Time) })
+	return reminders, nil
+}
+
+// SetReminders replaces all pending reminders by reminders for the configured milestones.
+func SetReminders(conf ReminderConfig, startTime time.Time, breakTime, targetTime time.Duration) ([]Reminder, error) {
+	conf = conf.WithDefaults()
+	scheduler, err := newReminderScheduler(conf.Scheduler)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders, err := ComputeReminders(conf.Milestones, startTime, breakTime, targetTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ClearReminders(); err != nil {
+		return nil, err
+	}
+	// also removes jobs that have been scheduled by older versions without state file
+	if err := scheduler.Clear(nil); err != nil {
+		return nil, fmt.Errorf("clear %s reminders: %s", conf.Scheduler, err.Error())
+	}
+
+	now := time.Now()
+	scheduled := make([]Reminder, 0, len(reminders))
+	for _, r := range reminders {
+		if r.Time.Before(now) {
+			stdio.Debug("skip reminder %q at %s in the past", r.Milestone, r.Time.Format("15:04"))
+			continue
+		}
+		r.Scheduler = conf.Scheduler
+		r.JobID, err = scheduler.Schedule(r)
+		if err != nil {
+			return nil, fmt.Errorf("schedule reminder %q: %s", r.Milestone, err.Error())
+		}
+		scheduled = append(scheduled, r)
+	}
+	return scheduled, writeReminders(scheduled)
+}
+
+// ClearReminders removes all pending reminders from their schedulers.
+func ClearReminders() error {
+	reminders, err := readReminders()
+	if err != nil {
+		return err
+	}
+
+	byScheduler := make(map[string][]Reminder)
+	for _, r := range reminders {
+		byScheduler[r.Scheduler] = append(byScheduler[r.Scheduler], r)
+	}
+	for name, rs := range byScheduler {
+		scheduler, err := newReminderScheduler(name)
+		if err != nil {
+			stdio.Warn("cannot clear reminders: %s", err.Error())
+			continue
+		}
+		if err := scheduler.Clear(rs); err != nil {
+			return fmt.Errorf("clear %s reminders: %s", name, err.Error())
+		}
+	}
+	return writeReminders(nil)
+}
+
+// NotifyReminder sends a reminder using all configured notifiers.
+func NotifyReminder(conf ReminderConfig, r Reminder) error {
+	conf = conf.WithDefaults()
+	var lastErr error
+	for _, name := range conf.Notifiers {
+		notifier, err := newReminderNotifier(name, conf)
+		if err == nil {
+			err = notifier.Notify(r)
+		}
+		if err != nil {
+			stdio.Warn("notify via %s failed: %s", name, err.Error())
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func remindersFile() string {
+	return filepath.Join(getConfigDir(), "reminders.json")
+}
+
+func readReminders() ([]Reminder, error) {
+	data, err := os.ReadFile(remindersFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var reminders []Reminder
+	if err := json.Unmarshal(data, &reminders); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func writeReminders(reminders []Reminder) error {
+	if reminders == nil {
+		reminders = []Reminder{}
+	}
+	data, err := json.MarshalIndent(reminders, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(getConfigDir(), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(remindersFile(), data, os.ModePerm)
+}
+
+func parseDurationHHMM(str string) (time.Duration, error) {
+	t, err := time.Parse("15:04", str)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func cmdRemindersList() error {
+	reminders, err := readReminders()
+	if err != nil {
+		return err
+	}
+	if len(reminders) == 0 {
+		stdio.Println("no reminders set")
+		return nil
+	}
+
+	now := time.Now()
+	for _, r := range reminders {
+		hint := ""
+		if r.Fired || r.Time.Before(now) {
+			hint = fmt.Sprintf(" %s(past)%s", colors.CacheHint, colorEnd)
+		}
+		stdio.Println("%s  %-8s %-8s %s%s", r.Time.Format("15:04"), r.Milestone, r.Scheduler, r.Message, hint)
+	}
+	return nil
+}
+
+func cmdRemindersClear() error {
+	if err := ClearReminders(); err != nil {
+		return err
+	}
+	stdio.Println("reminders have been cleared")
+	return nil
+}
+
+func cmdRemindersNotify() error {
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		stdio.Warn("read user config failed: %s", err.Error())
+	}
+	return NotifyReminder(usrConf.Reminders, Reminder{
+		Milestone: cli.Reminders.Notify.Milestone,
+		Time:      time.Now(),
+		Message:   cli.Reminders.Notify.Message,
+	})
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	reminderNotificationTitle = "gohome"
+	reminderWebhookTimeout    = 10 * time.Second
+)
+
+// ReminderNotifier delivers a reminder to the user.
+type ReminderNotifier interface {
+	Notify(r Reminder) error
+}
+
+func newReminderNotifier(name string, conf ReminderConfig) (ReminderNotifier, error) {
+	switch name {
+	case "notify-send":
+		return notifySendNotifier{}, nil
+	case "dbus":
+		return dbusNotifier{}, nil
+	case "hook":
+		if len(conf.HookCommand) == 0 {
+			return nil, fmt.Errorf("HookCommand is not configured")
+		}
+		return hookNotifier{Command: conf.HookCommand}, nil
+	case "webhook":
+		if len(conf.WebhookURL) == 0 {
+			return nil, fmt.Errorf("WebhookURL is not configured")
+		}
+		return webhookNotifier{URL: conf.WebhookURL}, nil
+	default:
+		return nil, fmt.Errorf("unknown reminder notifier %q", name)
+	}
+}
+
+// notifySendNotifier calls notify-send from libnotify.
+type notifySendNotifier struct{}
+
+func (notifySendNotifier) Notify(r Reminder) error {
+	out, err := exec.Command("notify-send", "-i", "error", r.Message).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("notify-send: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// dbusNotifier talks to org.freedesktop.Notifications on the session bus.
+type dbusNotifier struct{}
+
+func (dbusNotifier) Notify(r Reminder) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("connect session bus: %s", err.Error())
+	}
+	defer conn.Close()
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := obj.Call("org.freedesktop.Notifications.Notify", 0,
+		reminderNotificationTitle, uint32(0), "error", reminderNotificationTitle, r.Message,
+		[]string{}, map[string]dbus.Variant{"urgency": dbus.MakeVariant(byte(2))}, int32(-1))
+	return call.Err
+}
+
+// hookNotifier runs a shell command with the reminder passed in environment variables.
+type hookNotifier struct {
+	Command string
+}
+
+func (n hookNotifier) Notify(r Reminder) error {
+	cmd := exec.Command("sh", "-c", n.Command)
+	cmd.Env = append(os.Environ(),
+		"GOHOME_MILESTONE="+r.Milestone,
+		"GOHOME_MESSAGE="+r.Message,
+		"GOHOME_TIME="+r.Time.Format(time.RFC3339),
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("hook: %s: %s", err.Error(), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// webhookNotifier posts the reminder as JSON.
+type webhookNotifier struct {
+	URL string
+}
+
+func (n webhookNotifier) Notify(r Reminder) error {
+	data, err := json.Marshal(map[string]string{
+		"milestone": r.Milestone,
+		"message":   r.Message,
+		"time":      r.Time.Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: reminderWebhookTimeout}
+	resp, err := client.Post(n.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielb42/goat"
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+const (
+	reminderAtQueue    = "g"
+	reminderUnitPrefix = "gohome-reminder-"
+)
+
+// ReminderScheduler triggers "gohome reminders notify" at the time of a reminder.
+type ReminderScheduler interface {
+	// Schedule registers a reminder and returns an id to identify the job.
+	Schedule(r Reminder) (string, error)
+	// Clear removes the given reminders that have been scheduled before.
+	Clear(reminders []Reminder) error
+}
+
+func newReminderScheduler(name string) (ReminderScheduler, error) {
+	switch name {
+	case "at":
+		return atScheduler{}, nil
+	case "systemd":
+		return systemdScheduler{}, nil
+	case "daemon":
+		return daemonScheduler{}, nil
+	default:
+		return nil, fmt.Errorf("unknown reminder scheduler %q", name)
+	}
+}
+
+// atScheduler uses the at queue "g" via goat.
+type atScheduler struct{}
+
+func (atScheduler) Schedule(r Reminder) (string, error) {
+	cmd, err := reminderNotifyCommand(r)
+	if err != nil {
+		return "", err
+	}
+	id, err := goat.AddJob(cmd, r.Time, reminderAtQueue)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(id), nil
+}
+
+func (atScheduler) Clear([]Reminder) error {
+	return goat.ClearQueue(reminderAtQueue)
+}
+
+// systemdScheduler creates transient systemd user timers.
+type systemdScheduler struct{}
+
+func (systemdScheduler) Schedule(r Reminder) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	unit := systemdUnitName(r)
+	out, err := exec.Command("systemd-run", "--user", "--quiet",
+		"--unit", unit,
+		"--on-calendar", r.Time.Format("2006-01-02 15:04:05"),
+		"--timer-property", "AccuracySec=1s",
+		exe, "reminders", "notify", "--milestone", r.Milestone, "--", r.Message).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("systemd-run: %s", strings.TrimSpace(string(out)))
+	}
+	return unit, nil
+}
+
+// systemdUnitName returns a unit name that is valid for any milestone name. The milestone is identified by a hash, its name is only passed to the notify command.
+func systemdUnitName(r Reminder) string {
+	h := fnv.New32a()
+	h.Write([]byte(r.Milestone))
+	return fmt.Sprintf("%s%08x-%d", reminderUnitPrefix, h.Sum32(), r.Time.Unix())
+}
+
+func (systemdScheduler) Clear(reminders []Reminder) error {
+	for _, r := range reminders {
+		if len(r.JobID) == 0 {
+			continue
+		}
+		if out, err := exec.Command("systemctl", "--user", "stop", r.JobID+".timer").CombinedOutput(); err != nil {
+			// the timer is gone after it has elapsed
+			stdio.Debug("stop timer %s: %s", r.JobID, strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// daemonScheduler only records reminders in the state file. They are fired by "gohome reminders watch".
+type daemonScheduler struct{}
+
+func (daemonScheduler) Schedule(r Reminder) (string, error) {
+	return fmt.Sprintf("%s-%d", r.Milestone, r.Time.Unix()), nil
+}
+
+func (daemonScheduler) Clear([]Reminder) error {
+	return nil
+}
+
+// reminderNotifyCommand returns a shell command that sends the reminder using this executable.
+func reminderNotifyCommand(r Reminder) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{shellQuote(exe), "reminders", "notify", "--milestone", shellQuote(r.Milestone), "--", shellQuote(r.Message)}, " "), nil
+}
+
+func shellQuote(str string) string {
+	return "'" + strings.ReplaceAll(str, "'", `'\''`) + "'"
+}
+
+// FireDueReminders notifies all due reminders of the daemon scheduler that have not been fired yet. Reminders older than daemonReminderGrace, e.g. after suspend, are marked as fired without notification.
+func FireDueReminders(conf ReminderConfig, now time.Time) (int, error) {
+	reminders, err := readReminders()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	var changed bool
+	for i, r := range reminders {
+		if r.Scheduler != "daemon" || r.Fired || r.Time.After(now) {
+			continue
+		}
+		reminders[i].Fired = true
+		changed = true
+		if now.Sub(r.Time) > daemonReminderGrace {
+			stdio.Debug("skip outdated reminder %q at %s", r.Milestone, r.Time.Format("15:04"))
+			continue
+		}
+		if err := NotifyReminder(conf, r); err != nil {
+			stdio.Warn("reminder %q: %s", r.Milestone, err.Error())
+		}
+		count++
+	}
+	if changed {
+		return count, writeReminders(reminders)
+	}
+	return 0, nil
+}
+
+func cmdRemindersWatch() error {
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		return fmt.Errorf("read user config: %s", err.Error())
+	}
+
+	stdio.Info("watching reminders every %s", cli.Reminders.Watch.Interval)
+	ticker := time.NewTicker(cli.Reminders.Watch.Interval)
+	defer ticker.Stop()
+	for {
+		if n, err := FireDueReminders(usrConf.Reminders, time.Now()); err != nil {
+			stdio.Warn("fire reminders: %s", err.Error())
+		} else if n > 0 {
+			stdio.Debug("fired %d reminders", n)
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeReminders(t *testing.T) {
+	start := time.Date(2026, time.October, 14, 8, 0, 0, 0, time.Local)
+	reminders, err := ComputeReminders(defaultReminderMilestones(), start, 45*time.Minute, 8*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, reminders, 2)
+
+	assert.Equal(t, "gohome", reminders[0].Milestone)
+	assert.Equal(t, time.Date(2026, time.October, 14, 16, 45, 0, 0, time.Local), reminders[0].Time)
+	assert.Equal(t, "10h", reminders[1].Milestone)
+	assert.Equal(t, time.Date(2026, time.October, 14, 18, 30, 0, 0, time.Local), reminders[1].Time)
+}
+
+func TestComputeRemindersInvalid(t *testing.T) {
+	start := time.Date(2026, time.October, 14, 8, 0, 0, 0, time.Local)
+	_, err := ComputeReminders([]ReminderMilestone{{Name: "x", WorkTime: "8h"}}, start, 0, 8*time.Hour)
+	assert.Error(t, err)
+	_, err = ComputeReminders([]ReminderMilestone{{Name: "x", WorkTime: "09:00", LeadTime: "-5"}}, start, 0, 8*time.Hour)
+	assert.Error(t, err)
+}
+
+func TestSetRemindersDaemon(t *testing.T) {
+	dir := useTempConfigDir(t)
+	hookFile := filepath.Join(dir, "hook.txt")
+	conf := ReminderConfig{
+		Scheduler:   "daemon",
+		Notifiers:   []string{"hook"},
+		HookCommand: `echo "$GOHOME_MILESTONE:$GOHOME_MESSAGE" >> ` + shellQuote(hookFile),
+		Milestones: []ReminderMilestone{
+			{Name: "past", WorkTime: "00:01", Message: "too late"},
+			{Name: "soon", WorkTime: "00:30", LeadTime: "00:10", Message: "almost there"},
+		},
+	}
+
+	start := time.Now().Add(-time.Hour)
+	reminders, err := SetReminders(conf, start, 0, 8*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, reminders, 0)
+
+	start = time.Now().Add(-5 * time.Minute)
+	reminders, err = SetReminders(conf, start, 0, 8*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, "soon", reminders[0].Milestone)
+	assert.Equal(t, "daemon", reminders[0].Scheduler)
+
+	n, err := FireDueReminders(conf, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = FireDueReminders(conf, reminders[0].Time.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = FireDueReminders(conf, reminders[0].Time.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	data, err := os.ReadFile(hookFile)
+	require.NoError(t, err)
+	assert.Equal(t, "soon:almost there\n", string(data))
+
+	require.NoError(t, ClearReminders())
+	reminders, err = readReminders()
+	require.NoError(t, err)
+	assert.Empty(t, reminders)
+
+	// reminders missed during suspend are not fired late
+	reminders, err = SetReminders(conf, start, 0, 8*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	n, err = FireDueReminders(conf, reminders[0].Time.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	reminders, err = readReminders()
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	assert.True(t, reminders[0].Fired)
+
+	data, err = os.ReadFile(hookFile)
+	require.NoError(t, err)
+	assert.Equal(t, "soon:almost there\n", string(data))
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+	}))
+	defer server.Close()
+
+	notifier, err := newReminderNotifier("webhook", ReminderConfig{WebhookURL: server.URL})
+	require.NoError(t, err)
+	require.NoError(t, notifier.Notify(Reminder{Milestone: "gohome", Message: "Go home!", Time: time.Now()}))
+	assert.Equal(t, "gohome", payload["milestone"])
+	assert.Equal(t, "Go home!", payload["message"])
+
+	_, err = newReminderNotifier("webhook", ReminderConfig{})
+	assert.Error(t, err)
+}
+
+func TestSystemdUnitName(t *testing.T) {
+	at := time.Unix(1792000000, 0)
+	unit := systemdUnitName(Reminder{Milestone: "Feierabend / 10h ö", Time: at})
+	assert.Regexp(t, `^gohome-reminder-[0-9a-f]{8}-1792000000$`, unit)
+	assert.Equal(t, unit, systemdUnitName(Reminder{Milestone: "Feierabend / 10h ö", Time: at}))
+	assert.NotEqual(t, unit, systemdUnitName(Reminder{Milestone: "10h", Time: at}))
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'it'\''s time'`, shellQuote("it's time"))
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+var (
+	serveHTMLTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+		"duration": formatDurationMinutes,
+		"flexi":    formatSignedDurationMinutes,
+		"clock":    func(t time.Time) string { return t.Format("15:04") },
+	}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="60">
+<title>gohome</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+td, th { padding: 0.2em 1em; text-align: left; }
+.error { color: #c00; }
+.target { font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>gohome</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{with .Status}}
+<table>
+<tr><th>state</th><td>{{.State}}</td></tr>
+<tr><th>worktime</th><td>{{duration .AccountedWorkTime}} ({{flexi .FlexiTime}})</td></tr>
+<tr><th>break</th><td>{{duration .AccountedBreakTime}}</td></tr>
+<tr><th>flexi-time balance</th><td>{{flexi .FlexiTimeBalance}} &rarr; {{flexi .NewFlexiTimeBalance}}</td></tr>
+</table>
+<h2>Leave times</h2>
+<table>
+{{range .LeaveTimes}}<tr{{if .IsTarget}} class="target"{{end}}><th>{{duration .WorkTime}}</th><td>{{clock .Time}}</td><td>{{duration .BreakTime}} break</td></tr>
+{{end}}</table>
+<p>Entries refreshed at {{clock .CacheTime}}.</p>
+{{end}}
+</body>
+</html>
+`))
+)
+
+// newServeHandler returns the HTTP handler for the status page, the JSON api and Prometheus metrics.
+func newServeHandler(d *daemon) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := serveHTMLTemplate.Execute(w, d.Current()); err != nil {
+			stdio.Warn("render status page: %s", err.Error())
+		}
+	})
+	mux.HandleFunc("GET /api/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(d.Current()); err != nil {
+			stdio.Warn("write status: %s", err.Error())
+		}
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w, d.Current())
+	})
+	return mux
+}
+
+// writeMetrics writes the status in Prometheus text exposition format.
+func writeMetrics(w io.Writer, status DaemonStatus) {
+	metric := func(name, help string, value float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		fmt.Fprintf(w, "%s %g\n", name, value)
+	}
+
+	var refreshError float64
+	if len(status.Error) > 0 {
+		refreshError = 1
+	}
+	metric("gohome_refresh_error", "Whether the last refresh from Matrix failed.", refreshError)
+	if !status.LastRefresh.IsZero() {
+		metric("gohome_last_refresh_timestamp", "Time of the last successful refresh from Matrix.", float64(status.LastRefresh.Unix()))
+	}
+
+	s := status.Status
+	if s == nil {
+		return
+	}
+	var ticking float64
+	if s.Ticking() {
+		ticking = 1
+	}
+	metric("gohome_ticking", "Whether the clock is currently running.", ticking)
+	metric("gohome_worktime_seconds", "Accounted work time of today.", s.AccountedWorkTime.Seconds())
+	metric("gohome_break_seconds", "Accounted break time of today.", s.AccountedBreakTime.Seconds())
+	metric("gohome_target_time_seconds", "Target work time of today.", s.TargetTime.Seconds())
+	metric("gohome_flexi_balance_seconds", "Flexi-time balance including today.", s.NewFlexiTimeBalance().Seconds())
+
+	fmt.Fprintf(w, "# HELP gohome_leave_time_timestamp Earliest leave time for an accounted work time.\n# TYPE gohome_leave_time_timestamp gauge\n")
+	for _, lt := range s.LeaveTimes {
+		target := formatDurationMinutes(lt.WorkTime)
+		if lt.IsTarget {
+			target = ReminderWorkTimeTarget
+		}
+		fmt.Fprintf(w, "gohome_leave_time_timestamp{target=%q} %d\n", target, lt.Time.Unix())
+	}
+}
+
+func cmdServe() error {
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		stdio.Warn("read user config failed: %s", err.Error())
+	}
+	targetTime, err := parseTargetTime(cli.Serve.TargetTime, usrConf)
+	if err != nil {
+		return err
+	}
+	matrixConfig, err := getMatrixConfigWithPassword()
+	if err != nil {
+		return err
+	}
+
+	d := newDaemon(cachingMatrixFetcher(matrixConfig), targetTime, ReminderConfig{})
+	d.reminders.Milestones = nil
+
+	server := &http.Server{Addr: cli.Serve.Listen, Handler: newServeHandler(d), ReadHeaderTimeout: 10 * time.Second}
+	stop := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+		close(stop)
+		server.Close()
+	}()
+	go d.Run(cli.Serve.Refresh, stop)
+
+	stdio.Info("listening on http://%s", cli.Serve.Listen)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	d := newTestDaemon([]Entry{{Type: EntryTypeCome, Time: time.Now().Add(-time.Hour)}})
+	d.Refresh()
+	server := httptest.NewServer(newServeHandler(d))
+	defer server.Close()
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	resp, body := get("/api/status")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var status DaemonStatus
+	require.NoError(t, json.Unmarshal([]byte(body), &status))
+	require.NotNil(t, status.Status)
+	assert.Equal(t, EntryTypeCome, status.Status.State)
+
+	_, body = get("/metrics")
+	assert.Contains(t, body, "# TYPE gohome_worktime_seconds gauge")
+	assert.Contains(t, body, "gohome_flexi_balance_seconds ")
+	assert.Contains(t, body, `gohome_leave_time_timestamp{target="target"} `)
+	assert.Contains(t, body, `gohome_leave_time_timestamp{target="10:00"} `)
+	assert.Contains(t, body, "gohome_refresh_error 0\n")
+
+	resp, body = get("/")
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, body, "Leave times")
+
+	resp, _ = get("/unknown")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	showTemplateFile = "show.tmpl"
+	// defaultShowTemplate reproduces the classic output of "gohome show".
+	defaultShowTemplate = `{{range .Entries -}}
+{{if eq .Type "come"}} {{color "ComeEntry"}}--> {{clock .Time}}{{reset}}
+{{else if eq .Type "leave"}} {{color "LeaveEntry"}}<-- {{clock .Time}}{{reset}}
+{{else if eq .Type "trip"}} {{color "TripEntry"}}<-- {{clock .Time}} DG{{reset}}
+{{end}}{{end -}}
+{{separator}}
+time now:            {{clock .Now}}{{if .Cached}} {{color "CacheHint"}}(cache from {{.CacheTime.Format "15:04:05"}}){{reset}}{{end}}
+worktime:            {{color "WorkTime"}}{{durationSeconds .AccountedWorkTime}}{{reset}} ({{flexi .FlexiTime}})
+{{if ne (duration .AccountedBreakTime) (duration .BreakTime) -}}
+{{color "BreakEntry"}}break:               {{duration .AccountedBreakTime}} (taken {{duration .BreakTime}}){{reset}}
+{{else -}}
+{{color "BreakEntry"}}break:               {{duration .AccountedBreakTime}}{{reset}}
+{{end -}}
+{{with .Break}}{{color "BreakInfo"}}{{if .Length}}take break:          {{duration .Length}}, start by {{clock .LatestStart}} (deducted anyway){{else}}take break:          none, a further break only extends your presence{{end}}{{reset}}
+{{end -}}
+flexi-time balance: {{flexi .FlexiTimeBalance}} -> {{flexi .NewFlexiTimeBalance}}
+{{separator}}
+{{range .LeaveTimes}}{{if not .IsTarget}}{{duration .WorkTime}} at {{clock .Time}} {{color "BreakInfo"}}({{duration .BreakTime}} break){{reset}}
+{{end}}{{end -}}
+{{separator}}
+{{with .TargetLeaveTime}}go home ({{duration .WorkTime}}) at {{color "LeaveTime"}}{{clock .Time}}{{reset}} {{color "BreakInfo"}}({{duration .BreakTime}} break){{reset}}{{end}}
+{{with .Tomorrow}}{{separator}}
+earliest start tomorrow: {{clock .Start}}{{if .Projected}} {{color "BreakInfo"}}(leaving at {{clock .Leave}}){{reset}}{{end}}
+{{if not .LeaveTime.IsZero}}go home tomorrow ({{duration .TargetTime}}) at {{color "LeaveTime"}}{{clock .LeaveTime}}{{reset}} {{color "BreakInfo"}}({{duration .BreakTime}} break){{reset}}
+{{end}}{{end -}}
+`
+	compactShowTemplate = `{{range $i, $e := .Entries}}{{if $i}} {{end}}{{if eq .Type "come"}}{{color "ComeEntry"}}>{{clock .Time}}{{else}}{{color "LeaveEntry"}}<{{clock .Time}}{{if eq .Type "trip"}} DG{{end}}{{end}}{{reset}}{{end}}
+work {{color "WorkTime"}}{{duration .AccountedWorkTime}}{{reset}} ({{flexi .FlexiTime}}) | break {{duration .AccountedBreakTime}} | balance {{flexi .NewFlexiTimeBalance}}
+{{range $i, $lt := .LeaveTimes}}{{if $i}} | {{end}}{{if .IsTarget}}{{color "LeaveTime"}}home {{clock .Time}}{{reset}}{{else}}{{duration .WorkTime}} {{clock .Time}}{{end}}{{end}}
+`
+	minimalShowTemplate = `{{duration .AccountedWorkTime}} -> {{clock .TargetLeaveTime.Time}}
+`
+)
+
+var (
+	builtinShowTemplates = map[string]string{
+		"default": defaultShowTemplate,
+		"compact": compactShowTemplate,
+		"minimal": minimalShowTemplate,
+	}
+)
+
+// showData is passed to show templates.
+type showData struct {
+	*Status
+	// Now is the time of rendering.
+	Now time.Time
+	// Cached is true if entries have been read from cache. CacheTime is the time of the cache then.
+	Cached bool
+	// Tomorrow is the earliest start and leave time of the next day. It is only set if the rest period delays tomorrow's start or a target for tomorrow is given.
+	Tomorrow *TomorrowPlan
+	// Break is the recommended break for reaching the target time. It is only set while the clock is ticking.
+	Break *BreakRecommendation
+}
+
+// showTemplateFuncs returns the helper functions available in show templates.
+func showTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"duration":        formatDurationMinutes,
+		"durationSeconds": formatDurationSeconds,
+		"signed":          formatSignedDurationMinutes,
+		"flexi":           formatFlexiTime,
+		"clock":           func(t time.Time) string { return t.Format("15:04") },
+		"color":           colorByName,
+		"reset":           func() string { return colorEnd },
+		"separator":       func() string { return strings.Repeat("-", 53) },
+		"repeat":          func(count int, str string) string { return strings.Repeat(str, count) },
+		"upper":           strings.ToUpper,
+		"lower":           strings.ToLower,
+	}
+}
+
+// colorByName returns the escape sequence of a field of colorsDef like "LeaveTime".
+func colorByName(name string) (string, error) {
+	field := reflect.ValueOf(colors).FieldByName(name)
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return "", fmt.Errorf("unknown color %q", name)
+	}
+	return field.String(), nil
+}
+
+// loadShowTemplate returns the built-in template of the given name or parses the given file. Without a name, show.tmpl from the config dir is used if present.
+func loadShowTemplate(name string) (*template.Template, error) {
+	if len(name) == 0 {
+		file := filepath.Join(getConfigDir(), showTemplateFile)
+		if _, err := os.Stat(file); err == nil {
+			name = file
+		} else {
+			name = "default"
+		}
+	}
+
+	if str, ok := builtinShowTemplates[name]; ok {
+		return template.New(name).Funcs(showTemplateFuncs()).Parse(str)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("template %q is neither built-in (%s) nor a readable file: %s", name, strings.Join(builtinShowTemplateNames(), ", "), err.Error())
+	}
+	tmpl, err := template.New(filepath.Base(name)).Funcs(showTemplateFuncs()).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %s", err.Error())
+	}
+	return tmpl, nil
+}
+
+func builtinShowTemplateNames() []string {
+	names := make([]string, 0, len(builtinShowTemplates))
+	for name := range builtinShowTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func renderShowTemplate(w io.Writer, tmpl *template.Template, data showData) error {
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("render template: %s", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testShowData(t *testing.T) showData {
+	day := time.Date(2026, time.October, 14, 0, 0, 0, 0, time.Local)
+	status, err := ComputeStatus([]Entry{
+		{Type: EntryTypeCome, Time: day.Add(8 * time.Hour)},
+		{Type: EntryTypeLeave, Time: day.Add(12 * time.Hour)},
+		{Type: EntryTypeCome, Time: day.Add(12*time.Hour + 30*time.Minute)},
+		{Type: EntryTypeLeave, Time: day.Add(17 * time.Hour)},
+	}, time.Hour, 8*time.Hour, nil)
+	require.NoError(t, err)
+	return showData{Status: status, Now: day.Add(17 * time.Hour)}
+}
+
+func renderTestTemplate(t *testing.T, name string) string {
+	tmpl, err := loadShowTemplate(name)
+	require.NoError(t, err)
+	var sb strings.Builder
+	require.NoError(t, renderShowTemplate(&sb, tmpl, testShowData(t)))
+	return sb.String()
+}
+
+func TestBuiltinShowTemplates(t *testing.T) {
+	oldColors, oldColorEnd := colors, colorEnd
+	disableColors()
+	defer func() { colors, colorEnd = oldColors, oldColorEnd }()
+
+	assert.Equal(t, ` --> 08:00
+ <-- 12:00
+ --> 12:30
+ <-- 17:00
+-----------------------------------------------------
+time now:            17:00
+worktime:            08:30:00 (+00:30)
+break:               00:30
+flexi-time balance: +01:00 -> +01:30
+-----------------------------------------------------
+06:00 at 14:30 (00:30 break)
+09:00 at 17:30 (00:30 break)
+10:00 at 18:45 (00:45 break)
+-----------------------------------------------------
+go home (08:00) at 16:30 (00:30 break)
+`, renderTestTemplate(t, "default"))
+
+	assert.Equal(t, `>08:00 <12:00 >12:30 <17:00
+work 08:30 (+00:30) | break 00:30 | balance +01:30
+06:00 14:30 | home 16:30 | 09:00 17:30 | 10:00 18:45
+`, renderTestTemplate(t, "compact"))
+
+	assert.Equal(t, "08:30 -> 16:30\n", renderTestTemplate(t, "minimal"))
+}
+
+func TestCustomShowTemplate(t *testing.T) {
+	dir := useTempConfigDir(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, showTemplateFile), []byte(`{{upper (printf "%s" .State)}} {{signed .FlexiTime}}{{color "LeaveTime"}}`), 0600))
+	oldColors := colors
+	defer func() { colors = oldColors }()
+	colors.LeaveTime = "<lt>"
+
+	assert.Equal(t, "LEAVE +00:30<lt>", renderTestTemplate(t, ""))
+
+	_, err := loadShowTemplate("fancy")
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, showTemplateFile), []byte(`{{color "Purple"}}`), 0600))
+	tmpl, err := loadShowTemplate("")
+	require.NoError(t, err)
+	assert.Error(t, renderShowTemplate(&strings.Builder{}, tmpl, testShowData(t)))
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+const (
+	// statsChartHours is the work time filling the whole bar of a day.
+	statsChartHours = 10
+	// statsChartWidth is the number of characters of a full bar.
+	statsChartWidth = 40
+)
+
+var (
+	statsBarBlocks   = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+	statsSparkBlocks = []string{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+	statsASCIISpark  = []string{"_", ".", "-", "=", "#"}
+)
+
+// Stats contains aggregated values of a period. Averages only consider finished days.
+type Stats struct {
+	From           time.Time
+	To             time.Time
+	Days           []DaySummary
+	FinishedDays   int
+	IncompleteDays int
+	// AvgArrival and AvgLeave are given as time since midnight.
+	AvgArrival   time.Duration
+	AvgLeave     time.Duration
+	AvgWorkTime  time.Duration
+	AvgBreakTime time.Duration
+	// DaysOver9h and DaysOver10h count days by actual work time before break deduction.
+	DaysOver9h  int
+	DaysOver10h int
+	FlexiTime   time.Duration
+	// Balances contains the flexi-time balance after each day. It is nil if no balance is known from the history.
+	Balances []time.Duration
+	Weeks    []WeekStats
+}
+
+// WeekStats contains the accounted work time of a calendar week.
+type WeekStats struct {
+	Year      int
+	Week      int
+	Days      int
+	WorkTime  time.Duration
+	FlexiTime time.Duration
+}
+
+// ComputeStats aggregates all days between from and to (inclusive) from the history.
+func ComputeStats(h *History, from, to time.Time, targetTime time.Duration) Stats {
+	stats := Stats{From: truncateDay(from), To: truncateDay(to)}
+
+	var arrival, leave, workTime, breakTime time.Duration
+	knownBalances := make(map[int]time.Duration)
+	for _, date := range h.Dates(from, to) {
+		day, _ := h.Get(date)
+		s, err := SummarizeDay(date, day.Entries, targetTime)
+		if err != nil {
+			stdio.Warn("skip %s: %s", date.Format(historyDateLayout), err.Error())
+			continue
+		}
+		if day.FlexiTimeBalance != nil {
+			knownBalances[len(stats.Days)] = *day.FlexiTimeBalance
+		}
+		stats.Days = append(stats.Days, s)
+
+		if s.Incomplete {
+			stats.IncompleteDays++
+			continue
+		}
+		leaveTime, ok := s.Leave()
+		if !ok {
+			// today is still running and its flexi-time not final
+			continue
+		}
+		stats.FlexiTime += s.FlexiTime
+		stats.addToWeek(s)
+
+		comeTime, _ := s.Come()
+		stats.FinishedDays++
+		arrival += comeTime.Sub(truncateDay(comeTime))
+		leave += leaveTime.Sub(truncateDay(leaveTime))
+		workTime += s.AccountedWorkTime
+		breakTime += s.AccountedBreakTime
+		if s.WorkTime > 9*time.Hour {
+			stats.DaysOver9h++
+		}
+		if s.WorkTime > 10*time.Hour {
+			stats.DaysOver10h++
+		}
+	}
+
+	if stats.FinishedDays > 0 {
+		n := time.Duration(stats.FinishedDays)
+		stats.AvgArrival = arrival / n
+		stats.AvgLeave = leave / n
+		stats.AvgWorkTime = workTime / n
+		stats.AvgBreakTime = breakTime / n
+	}
+	stats.Balances = computeBalances(stats.Days, knownBalances)
+	return stats
+}
+
+func (stats *Stats) addToWeek(s DaySummary) {
+	year, week := s.Date.ISOWeek()
+	if len(stats.Weeks) == 0 || stats.Weeks[len(stats.Weeks)-1].Year != year || stats.Weeks[len(stats.Weeks)-1].Week != week {
+		stats.Weeks = append(stats.Weeks, WeekStats{Year: year, Week: week})
+	}
+	w := &stats.Weeks[len(stats.Weeks)-1]
+	w.Days++
+	w.WorkTime += s.AccountedWorkTime
+	w.FlexiTime += s.FlexiTime
+}
+
+// computeBalances returns the balance after each day. Known balances are the balances of the previous day as read from Matrix.
+func computeBalances(days []DaySummary, known map[int]time.Duration) []time.Duration {
+	first := -1
+	for i := range days {
+		if _, ok := known[i]; ok {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+
+	// derive the balance before the first day from the first known balance
+	balance := known[first]
+	for i := first - 1; i >= 0; i-- {
+		balance -= dayFlexiTime(days[i])
+	}
+
+	balances := make([]time.Duration, 0, len(days))
+	for i, s := range days {
+		if b, ok := known[i]; ok {
+			balance = b
+		}
+		balance += dayFlexiTime(s)
+		balances = append(balances, balance)
+	}
+	return balances
+}
+
+// dayFlexiTime returns the flexi-time of finished days. Incomplete days and today while still running count as zero like in the flexi-time total.
+func dayFlexiTime(s DaySummary) time.Duration {
+	if _, ok := s.Leave(); !ok {
+		return 0
+	}
+	return s.FlexiTime
+}
+
+// renderBar returns a horizontal bar of width chars per statsChartHours.
+func renderBar(d time.Duration, width int, ascii bool) string {
+	if d <= 0 {
+		return ""
+	}
+	// longer days are cut at the end of the chart
+	eighths := min(width*8, int(d*time.Duration(width)*8/(statsChartHours*time.Hour)))
+	if ascii {
+		return strings.Repeat("#", eighths/8)
+	}
+	return strings.Repeat("█", eighths/8) + statsBarBlocks[eighths%8]
+}
+
+// renderSparkline returns one character per value scaled between the minimum and maximum.
+func renderSparkline(values []time.Duration, ascii bool) string {
+	blocks := statsSparkBlocks
+	if ascii {
+		blocks = statsASCIISpark
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		index := 0
+		if hi > lo {
+			index = int(int64(v-lo) * int64(len(blocks)-1) / int64(hi-lo))
+		}
+		sb.WriteString(blocks[index])
+	}
+	return sb.String()
+}
+
+// parsePeriod returns the range of the current week, month or year until today.
+func parsePeriod(period string) (time.Time, time.Time, error) {
+	today := truncateDay(time.Now())
+	switch period {
+	case "week":
+		weekday := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -weekday), today, nil
+	case "month", "":
+		return today.AddDate(0, 0, 1-today.Day()), today, nil
+	case "year":
+		return time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, time.Local), today, nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period %q", period)
+	}
+}
+
+func cmdStats() error {
+	from, to, err := parsePeriod(cli.Stats.Period)
+	if err != nil {
+		return err
+	}
+	if len(cli.Stats.From) > 0 || len(cli.Stats.To) > 0 {
+		fromStr, toStr := cli.Stats.From, cli.Stats.To
+		if len(fromStr) == 0 {
+			fromStr = from.Format(historyDateLayout)
+		}
+		if from, to, err = parseDateRange(fromStr, toStr); err != nil {
+			return err
+		}
+	}
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		stdio.Warn("read user config failed: %s", err.Error())
+	}
+	targetTime, err := parseTargetTime(cli.Stats.TargetTime, usrConf)
+	if err != nil {
+		return err
+	}
+
+	h, err := ReadHistory()
+	if err != nil {
+		return fmt.Errorf("read history: %s", err.Error())
+	}
+	if cli.Stats.Fetch {
+		if err := fetchHistory(h, from, to); err != nil {
+			return err
+		}
+	}
+
+	stats := ComputeStats(h, from, to, targetTime)
+	if len(stats.Days) == 0 {
+		return fmt.Errorf("no days in history between %s and %s, use --fetch to query Matrix", from.Format(historyDateLayout), to.Format(historyDateLayout))
+	}
+	printStats(stats, targetTime, cli.Stats.ASCII)
+	return nil
+}
+
+func printStats(stats Stats, targetTime time.Duration, ascii bool) {
+	stdio.Println("period:              %s - %s (%d days, %d incomplete)", stats.From.Format(historyDateLayout), stats.To.Format(historyDateLayout), len(stats.Days), stats.IncompleteDays)
+	stdio.Println("average arrival:     %s", formatDurationMinutes(stats.AvgArrival))
+	stdio.Println("average leave:       %s", formatDurationMinutes(stats.AvgLeave))
+	stdio.Println("average worktime:    %s%s%s", colors.WorkTime, formatDurationMinutes(stats.AvgWorkTime), colorEnd)
+	stdio.Println("average break:       %s", formatDurationMinutes(stats.AvgBreakTime))
+	stdio.Println("days over 9h / 10h:  %d / %d", stats.DaysOver9h, stats.DaysOver10h)
+	stdio.Println("flexi-time:          %s", formatFlexiTime(stats.FlexiTime))
+	if len(stats.Balances) > 0 {
+		stdio.Println("flexi-time balance:  %s -> %s  %s", formatFlexiTime(stats.Balances[0]-dayFlexiTime(stats.Days[0])), formatFlexiTime(stats.Balances[len(stats.Balances)-1]), renderSparkline(stats.Balances, ascii))
+	}
+
+	stdio.Println("%s", strings.Repeat("-", 53))
+	targetWidth := int(targetTime * statsChartWidth / (statsChartHours * time.Hour))
+	for _, s := range stats.Days {
+		label := s.Date.Format("Mon 2006-01-02")
+		if s.Incomplete {
+			stdio.Println("%s  %s", label, colors.CacheHint+"missing leave booking"+colorEnd)
+			continue
+		}
+		raw := renderBar(s.AccountedWorkTime, statsChartWidth, ascii)
+		runes := []rune(raw)
+		bar := colors.WorkTime + raw + colorEnd
+		if len(runes) > targetWidth {
+			// highlight overtime beyond the target time
+			bar = colors.WorkTime + string(runes[:targetWidth]) + colors.FlexiTimePlus + string(runes[targetWidth:]) + colorEnd
+		}
+		padding := strings.Repeat(" ", max(0, statsChartWidth+1-len(runes)))
+		stdio.Println("%s  %s%s%s %s", label, bar, padding, formatDurationMinutes(s.AccountedWorkTime), formatFlexiTime(s.FlexiTime))
+	}
+
+	if len(stats.Weeks) > 1 {
+		stdio.Println("%s", strings.Repeat("-", 53))
+		for _, w := range stats.Weeks {
+			stdio.Println("week %02d/%d:  %d days  %s  %s", w.Week, w.Year, w.Days, formatDurationMinutes(w.WorkTime), formatFlexiTime(w.FlexiTime))
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStats(t *testing.T) {
+	from := time.Date(2026, time.September, 7, 0, 0, 0, 0, time.Local)
+	at := func(day, hour, minute int) time.Time {
+		return from.AddDate(0, 0, day).Add(dur(hour, minute))
+	}
+	h := &History{Days: make(map[string]HistoryDay)}
+	balance := dur(1, 0)
+	h.Put(at(0, 0, 0), []Entry{{EntryTypeCome, at(0, 8, 0)}, {EntryTypeLeave, at(0, 16, 30)}}, nil)
+	h.Put(at(1, 0, 0), []Entry{{EntryTypeCome, at(1, 7, 30)}, {EntryTypeLeave, at(1, 18, 0)}}, &balance)
+	h.Put(at(2, 0, 0), []Entry{{EntryTypeCome, at(2, 9, 0)}}, nil)
+	// next week
+	h.Put(at(7, 0, 0), []Entry{{EntryTypeCome, at(7, 8, 30)}, {EntryTypeLeave, at(7, 15, 30)}}, nil)
+
+	stats := ComputeStats(h, from, at(7, 0, 0), 8*time.Hour)
+	require.Len(t, stats.Days, 4)
+	assert.Equal(t, 3, stats.FinishedDays)
+	assert.Equal(t, 1, stats.IncompleteDays)
+	assert.Equal(t, dur(8, 0), stats.AvgArrival)
+	assert.Equal(t, dur(16, 40), stats.AvgLeave)
+	// 8:00 + 9:45 + 6:30
+	assert.Equal(t, dur(8, 5), stats.AvgWorkTime)
+	assert.Equal(t, 1, stats.DaysOver9h)
+	assert.Equal(t, 1, stats.DaysOver10h)
+	assert.Equal(t, dur(0, 15), stats.FlexiTime)
+
+	assert.Equal(t, []time.Duration{dur(1, 0), dur(2, 45), dur(2, 45), dur(1, 15)}, stats.Balances)
+	require.Len(t, stats.Weeks, 2)
+	assert.Equal(t, 2, stats.Weeks[0].Days)
+	assert.Equal(t, dur(1, 45), stats.Weeks[0].FlexiTime)
+}
+
+func TestComputeStatsRunningDay(t *testing.T) {
+	now := time.Now()
+	h := &History{Days: make(map[string]HistoryDay)}
+	balance := dur(1, 0)
+	h.Put(now, []Entry{{EntryTypeCome, now}}, &balance)
+
+	stats := ComputeStats(h, now, now, 8*time.Hour)
+	require.Len(t, stats.Days, 1)
+	assert.Equal(t, 0, stats.IncompleteDays)
+	assert.Equal(t, 0, stats.FinishedDays)
+	assert.Equal(t, time.Duration(0), stats.FlexiTime)
+	assert.Empty(t, stats.Weeks)
+	assert.Equal(t, []time.Duration{balance}, stats.Balances)
+}
+
+func TestRenderBar(t *testing.T) {
+	assert.Equal(t, "", renderBar(0, 40, false))
+	assert.Equal(t, "████", renderBar(12*time.Hour, 4, false))
+	assert.Equal(t, "████████████████████████████████", renderBar(8*time.Hour, 40, false))
+	assert.Equal(t, "█▌", renderBar(dur(0, 22)+30*time.Second, 40, false))
+	assert.Equal(t, "##", renderBar(dur(0, 30), 40, true))
+}
+
+func TestRenderSparkline(t *testing.T) {
+	assert.Equal(t, "▁▄█", renderSparkline([]time.Duration{0, time.Hour, 2 * time.Hour}, false))
+	assert.Equal(t, "__", renderSparkline([]time.Duration{time.Hour, time.Hour}, true))
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+var (
+	// statusLeaveTimeTargets are the work times for which leave times are computed in addition to the target time.
+	statusLeaveTimeTargets = []time.Duration{6 * time.Hour, 9 * time.Hour, 10 * time.Hour}
+
+	// worktimeLog logs how target and work times are computed.
+	worktimeLog = stdio.Logger("worktime")
+)
+
+// Status is the computed state of the current day as shown by "gohome show". Durations are encoded as minutes in JSON.
+type Status struct {
+	Time               time.Time
+	State              EntryType
+	Entries            []Entry
+	StartTime          time.Time
+	WorkTime           time.Duration
+	BreakTime          time.Duration
+	AccountedWorkTime  time.Duration
+	AccountedBreakTime time.Duration
+	TargetTime         time.Duration
+	FlexiTime          time.Duration
+	FlexiTimeBalance   time.Duration
+	// LeaveTimes contains the leave times for 6h, 9h and 10h and the target time, ordered by work time.
+	LeaveTimes []LeaveTime
+	CacheTime  time.Time
+}
+
+type jsonStatus struct {
+	Time                    time.Time   `json:"time"`
+	State                   EntryType   `json:"state"`
+	Entries                 []Entry     `json:"entries"`
+	StartTime               time.Time   `json:"start_time"`
+	WorkMinutes             int64       `json:"work_minutes"`
+	BreakMinutes            int64       `json:"break_minutes"`
+	AccountedWorkMinutes    int64       `json:"accounted_work_minutes"`
+	AccountedBreakMinutes   int64       `json:"accounted_break_minutes"`
+	TargetMinutes           int64       `json:"target_minutes"`
+	FlexiTimeMinutes        int64       `json:"flexi_time_minutes"`
+	FlexiTimeBalanceMinutes int64       `json:"flexi_time_balance_minutes"`
+	LeaveTimes              []LeaveTime `json:"leave_times"`
+	CacheTime               time.Time   `json:"cache_time,omitzero"`
+}
+
+// MarshalJSON encodes durations as minutes like the JSON export.
+func (s Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonStatus{
+		Time:                    s.Time,
+		State:                   s.State,
+		Entries:                 s.Entries,
+		StartTime:               s.StartTime,
+		WorkMinutes:             int64(s.WorkTime / time.Minute),
+		BreakMinutes:            int64(s.BreakTime / time.Minute),
+		AccountedWorkMinutes:    int64(s.AccountedWorkTime / time.Minute),
+		AccountedBreakMinutes:   int64(s.AccountedBreakTime / time.Minute),
+		TargetMinutes:           int64(s.TargetTime / time.Minute),
+		FlexiTimeMinutes:        int64(s.FlexiTime / time.Minute),
+		FlexiTimeBalanceMinutes: int64(s.FlexiTimeBalance / time.Minute),
+		LeaveTimes:              s.LeaveTimes,
+		CacheTime:               s.CacheTime,
+	})
+}
+
+// UnmarshalJSON decodes a status encoded by MarshalJSON, e.g. from the daemon socket.
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var js jsonStatus
+	if err := json.Unmarshal(data, &js); err != nil {
+		return err
+	}
+	*s = Status{
+		Time:               js.Time,
+		State:              js.State,
+		Entries:            js.Entries,
+		StartTime:          js.StartTime,
+		WorkTime:           time.Duration(js.WorkMinutes) * time.Minute,
+		BreakTime:          time.Duration(js.BreakMinutes) * time.Minute,
+		AccountedWorkTime:  time.Duration(js.AccountedWorkMinutes) * time.Minute,
+		AccountedBreakTime: time.Duration(js.AccountedBreakMinutes) * time.Minute,
+		TargetTime:         time.Duration(js.TargetMinutes) * time.Minute,
+		FlexiTime:          time.Duration(js.FlexiTimeMinutes) * time.Minute,
+		FlexiTimeBalance:   time.Duration(js.FlexiTimeBalanceMinutes) * time.Minute,
+		LeaveTimes:         js.LeaveTimes,
+		CacheTime:          js.CacheTime,
+	}
+	return nil
+}
+
+// LeaveTime is the earliest time to leave for a given accounted work time. Durations are encoded as minutes in JSON.
+type LeaveTime struct {
+	WorkTime  time.Duration
+	Time      time.Time
+	BreakTime time.Duration
+	IsTarget  bool
+}
+
+type jsonLeaveTime struct {
+	WorkMinutes  int64     `json:"work_minutes"`
+	Time         time.Time `json:"time"`
+	BreakMinutes int64     `json:"break_minutes"`
+	IsTarget     bool      `json:"is_target,omitempty"`
+}
+
+// MarshalJSON encodes durations as minutes like the JSON export.
+func (lt LeaveTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonLeaveTime{
+		WorkMinutes:  int64(lt.WorkTime / time.Minute),
+		Time:         lt.Time,
+		BreakMinutes: int64(lt.BreakTime / time.Minute),
+		IsTarget:     lt.IsTarget,
+	})
+}
+
+// UnmarshalJSON decodes a leave time encoded by MarshalJSON.
+func (lt *LeaveTime) UnmarshalJSON(data []byte) error {
+	var jlt jsonLeaveTime
+	if err := json.Unmarshal(data, &jlt); err != nil {
+		return err
+	}
+	*lt = LeaveTime{
+		WorkTime:  time.Duration(jlt.WorkMinutes) * time.Minute,
+		Time:      jlt.Time,
+		BreakTime: time.Duration(jlt.BreakMinutes) * time.Minute,
+		IsTarget:  jlt.IsTarget,
+	}
+	return nil
+}
+
+// Ticking returns true if the clock is currently running.
+func (s *Status) Ticking() bool {
+	return s.State == EntryTypeCome
+}
+
+// NewFlexiTimeBalance returns the flexi-time balance including today.
+func (s *Status) NewFlexiTimeBalance() time.Duration {
+	return s.FlexiTimeBalance + s.FlexiTime
+}
+
+// TargetLeaveTime returns the leave time for the target time.
+func (s *Status) TargetLeaveTime() LeaveTime {
+	for _, lt := range s.LeaveTimes {
+		if lt.IsTarget {
+			return lt
+		}
+	}
+	return LeaveTime{}
+}
+
+// ComputeStatus computes work time, flexi-time and leave times from today's entries. A non-nil simulatedBreakTime replaces the taken break.
+func ComputeStatus(entries []Entry, flexiTimeBalance, targetTime time.Duration, simulatedBreakTime *time.Duration) (*Status, error) {
+	if len(entries) == 0 {
+		return nil, ErrNoEntries
+	}
+
+	workTime, startTime, breakTime, err := ComputeWorkTime(entries)
+	if err != nil {
+		return nil, err
+	}
+	if simulatedBreakTime != nil {
+		workTime += breakTime - *simulatedBreakTime
+		breakTime = *simulatedBreakTime
+	}
+
+	accountedWorkTime, accountedBreakTime, err := ComputeAccountedWorkTime(workTime, breakTime)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Time:               time.Now(),
+		State:              entries[len(entries)-1].Type,
+		Entries:            entries,
+		StartTime:          startTime,
+		WorkTime:           workTime,
+		BreakTime:          breakTime,
+		AccountedWorkTime:  accountedWorkTime,
+		AccountedBreakTime: accountedBreakTime,
+		TargetTime:         targetTime,
+		FlexiTime:          noSeconds(accountedWorkTime) - targetTime,
+		FlexiTimeBalance:   flexiTimeBalance,
+	}
+
+	targets := append([]time.Duration{targetTime}, statusLeaveTimeTargets...)
+	for i, target := range targets {
+		t, err := GetLeaveTime(startTime, breakTime, target)
+		if err != nil {
+			return nil, err
+		}
+		status.LeaveTimes = append(status.LeaveTimes, LeaveTime{WorkTime: target, Time: t, BreakTime: t.Sub(startTime) - target, IsTarget: i == 0})
+	}
+	sort.SliceStable(status.LeaveTimes, func(i, j int) bool { return status.LeaveTimes[i].WorkTime < status.LeaveTimes[j].WorkTime })
+	return status, nil
+}
+
+// loadEntries returns today's entries from cache or Matrix and whether they have been read from cache.
+func loadEntries(forceReload bool, maxCacheAge time.Duration) ([]Entry, time.Duration, time.Time, bool, error) {
+	var entries []Entry
+	var flexiTimeBalance time.Duration
+	var cacheTime time.Time
+	var cacheOK bool
+	if !forceReload {
+		cacheLog.Debug("read cache")
+		var err error
+		entries, flexiTimeBalance, cacheTime, cacheOK, err = ReadCache(maxCacheAge)
+		if err != nil {
+			stdio.Warn("read cache failed: %s", err.Error())
+		} else if cacheOK {
+			if len(entries) == 0 {
+				cacheLog.Debug("no entries in cache, force update")
+				cacheOK = false
+			} else {
+				if entries[len(entries)-1].Type != EntryTypeCome {
+					cacheLog.Debug("latest entry in cache requires update", "type", entries[len(entries)-1].Type)
+					cacheOK = false
+				} else {
+					cacheLog.Debug("cache is valid")
+				}
+			}
+		}
+	}
+	if cacheOK {
+		return entries, flexiTimeBalance, cacheTime, true, nil
+	}
+
+	matrixConfig, err := getMatrixConfigWithPassword()
+	if err != nil {
+		return nil, 0, time.Time{}, false, err
+	}
+
+	matrixLog.Debug("fetch matrix entries")
+	entries, flexiTimeBalance, err = FetchMatrixEntries(matrixConfig)
+	if err != nil {
+		return nil, 0, time.Time{}, false, err
+	}
+
+	if err := WriteCache(entries, flexiTimeBalance); err != nil {
+		stdio.Warn("write cache failed: %s", err.Error())
+	} else {
+		cacheLog.Debug("cache written")
+	}
+	return entries, flexiTimeBalance, time.Time{}, false, nil
+}
+
+// parseTargetTime returns the target time from the given value in format "15:04", the user config or the default of 8 hours.
+func parseTargetTime(str string, usrConf UserConfig) (time.Duration, error) {
+	if len(str) == 0 {
+		str = usrConf.TargetTimeStr
+	}
+	if len(str) == 0 {
+		return 8 * time.Hour, nil
+	}
+	d, err := parseDurationHHMM(str)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse target time: %s", err.Error())
+	}
+	return d, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStatus(t *testing.T) {
+	day := time.Date(2026, time.October, 14, 0, 0, 0, 0, time.Local)
+	entries := []Entry{
+		{Type: EntryTypeCome, Time: day.Add(8 * time.Hour)},
+		{Type: EntryTypeLeave, Time: day.Add(12 * time.Hour)},
+		{Type: EntryTypeCome, Time: day.Add(12*time.Hour + 30*time.Minute)},
+		{Type: EntryTypeLeave, Time: day.Add(17 * time.Hour)},
+	}
+
+	status, err := ComputeStatus(entries, time.Hour, 8*time.Hour, nil)
+	require.NoError(t, err)
+	assert.Equal(t, EntryTypeLeave, status.State)
+	assert.False(t, status.Ticking())
+	assert.Equal(t, 8*time.Hour+30*time.Minute, status.WorkTime)
+	assert.Equal(t, 30*time.Minute, status.BreakTime)
+	assert.Equal(t, 30*time.Minute, status.FlexiTime)
+	assert.Equal(t, 90*time.Minute, status.NewFlexiTimeBalance())
+
+	require.Len(t, status.LeaveTimes, 4)
+	assert.Equal(t, 6*time.Hour, status.LeaveTimes[0].WorkTime)
+	target := status.TargetLeaveTime()
+	assert.True(t, target.IsTarget)
+	assert.Equal(t, day.Add(16*time.Hour+30*time.Minute), target.Time)
+
+	simulated := time.Hour
+	status, err = ComputeStatus(entries, time.Hour, 8*time.Hour, &simulated)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, status.BreakTime)
+	assert.Equal(t, 8*time.Hour, status.WorkTime)
+}
+
+func TestStatusJSON(t *testing.T) {
+	day := time.Date(2026, time.October, 14, 0, 0, 0, 0, time.Local)
+	status, err := ComputeStatus([]Entry{{Type: EntryTypeCome, Time: day.Add(8 * time.Hour)}, {Type: EntryTypeLeave, Time: day.Add(17 * time.Hour)}}, -90*time.Minute, 8*time.Hour, nil)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(status)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, 540.0, fields["work_minutes"])
+	assert.Equal(t, 30.0, fields["flexi_time_minutes"])
+	assert.Equal(t, -90.0, fields["flexi_time_balance_minutes"])
+	assert.NotContains(t, fields, "work_time")
+	assert.Equal(t, 360.0, fields["leave_times"].([]any)[0].(map[string]any)["work_minutes"])
+
+	var decoded Status
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, status.FlexiTimeBalance, decoded.FlexiTimeBalance)
+	assert.Equal(t, status.LeaveTimes[0].WorkTime, decoded.LeaveTimes[0].WorkTime)
+}
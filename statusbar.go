@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+const (
+	// StatusBarStopped is the class when the clock is not ticking.
+	StatusBarStopped = "stopped"
+	// StatusBarWorking is the class before reaching the target time.
+	StatusBarWorking = "working"
+	// StatusBarOvertime is the class after reaching the target time.
+	StatusBarOvertime = "overtime"
+	// StatusBarWarning is the class when approaching the 10h limit.
+	StatusBarWarning = "warning"
+	// StatusBarCritical is the class shortly before reaching the 10h limit.
+	StatusBarCritical = "critical"
+	// StatusBarError is the class when no status is available.
+	StatusBarError = "error"
+
+	statusBarWarningTime  = 9*time.Hour + 30*time.Minute
+	statusBarCriticalTime = 9*time.Hour + 45*time.Minute
+	// statusBarStaleCacheAge is the max age of a cache that is still shown when Matrix cannot be queried.
+	statusBarStaleCacheAge = 24 * time.Hour
+	// statusBarFetchBackoff is the time to wait after a failed fetch, repeated logins with a wrong password might lock the account.
+	statusBarFetchBackoff = 15 * time.Minute
+)
+
+var (
+	statusBarColors = map[string]string{
+		StatusBarStopped:  "#888888",
+		StatusBarWorking:  "#ffffff",
+		StatusBarOvertime: "#50fa7b",
+		StatusBarWarning:  "#ffb86c",
+		StatusBarCritical: "#ff5555",
+		StatusBarError:    "#ff5555",
+	}
+	statusBarFormatters = map[string]func(statusBarLine) string{
+		"plain":    formatStatusBarPlain,
+		"waybar":   formatStatusBarWaybar,
+		"i3blocks": formatStatusBarI3Blocks,
+		"polybar":  formatStatusBarPolybar,
+		"tmux":     formatStatusBarTmux,
+		"xbar":     formatStatusBarXbar,
+	}
+)
+
+// statusBarLine is the compact status for a status bar.
+type statusBarLine struct {
+	Text       string
+	Tooltip    string
+	Class      string
+	Percentage int
+}
+
+// newStatusBarLine returns a line like "6:42 ▸ 16:31" with the accounted work time and the leave time for the target time.
+func newStatusBarLine(status *Status) statusBarLine {
+	target := status.TargetLeaveTime()
+	line := statusBarLine{
+		Text:       fmt.Sprintf("%s ▸ %s", formatStatusBarDuration(status.AccountedWorkTime), target.Time.Format("15:04")),
+		Class:      StatusBarWorking,
+		Percentage: int(100 * status.AccountedWorkTime / (10 * time.Hour)),
+	}
+	switch {
+	case !status.Ticking():
+		line.Text = fmt.Sprintf("%s ■", formatStatusBarDuration(status.AccountedWorkTime))
+		line.Class = StatusBarStopped
+	case status.AccountedWorkTime >= statusBarCriticalTime:
+		line.Class = StatusBarCritical
+	case status.AccountedWorkTime >= statusBarWarningTime:
+		line.Class = StatusBarWarning
+	case status.AccountedWorkTime >= status.TargetTime:
+		line.Class = StatusBarOvertime
+	}
+
+	tooltip := []string{
+		fmt.Sprintf("worktime: %s (%s)", formatDurationMinutes(status.AccountedWorkTime), formatSignedDurationMinutes(status.FlexiTime)),
+		fmt.Sprintf("break: %s", formatDurationMinutes(status.AccountedBreakTime)),
+		fmt.Sprintf("flexi-time balance: %s", formatSignedDurationMinutes(status.NewFlexiTimeBalance())),
+	}
+	for _, lt := range status.LeaveTimes {
+		if lt.IsTarget {
+			tooltip = append(tooltip, fmt.Sprintf("go home (%s) at %s", formatDurationMinutes(lt.WorkTime), lt.Time.Format("15:04")))
+		} else {
+			tooltip = append(tooltip, fmt.Sprintf("%s at %s", formatDurationMinutes(lt.WorkTime), lt.Time.Format("15:04")))
+		}
+	}
+	if !status.CacheTime.IsZero() {
+		tooltip = append(tooltip, fmt.Sprintf("updated %s", status.CacheTime.Format("15:04")))
+	}
+	line.Tooltip = strings.Join(tooltip, "\n")
+	return line
+}
+
+func newStatusBarErrorLine(err error) statusBarLine {
+	return statusBarLine{Text: "gohome ⚠", Tooltip: err.Error(), Class: StatusBarError}
+}
+
+func formatStatusBarDuration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
+
+func formatStatusBarPlain(line statusBarLine) string {
+	return line.Text
+}
+
+func formatStatusBarWaybar(line statusBarLine) string {
+	data, _ := json.Marshal(struct {
+		Text       string `json:"text"`
+		Tooltip    string `json:"tooltip"`
+		Class      string `json:"class"`
+		Alt        string `json:"alt"`
+		Percentage int    `json:"percentage"`
+	}{line.Text, line.Tooltip, line.Class, line.Class, line.Percentage})
+	return string(data)
+}
+
+func formatStatusBarI3Blocks(line statusBarLine) string {
+	// full text, short text and color
+	return strings.Join([]string{line.Text, strings.SplitN(line.Text, " ", 2)[0], statusBarColors[line.Class]}, "\n")
+}
+
+func formatStatusBarPolybar(line statusBarLine) string {
+	return fmt.Sprintf("%%{F%s}%s%%{F-}", statusBarColors[line.Class], line.Text)
+}
+
+func formatStatusBarTmux(line statusBarLine) string {
+	return fmt.Sprintf("#[fg=%s]%s#[default]", statusBarColors[line.Class], line.Text)
+}
+
+func formatStatusBarXbar(line statusBarLine) string {
+	lines := []string{fmt.Sprintf("%s | color=%s", line.Text, statusBarColors[line.Class]), "---"}
+	lines = append(lines, strings.Split(line.Tooltip, "\n")...)
+	return strings.Join(lines, "\n")
+}
+
+// loadStatusBarEntries reads the cache and only queries Matrix if the cache is outdated and the password is stored or available from the environment or a password file. Password managers are not run, they might ask for a passphrase on every poll. After a failed fetch, Matrix is not queried again for statusBarFetchBackoff.
+func loadStatusBarEntries(maxCacheAge time.Duration) ([]Entry, time.Duration, time.Time, error) {
+	entries, flexiTimeBalance, cacheTime, ok, err := ReadCache(maxCacheAge)
+	if err != nil {
+		return nil, 0, time.Time{}, err
+	}
+	if ok {
+		return entries, flexiTimeBalance, cacheTime, nil
+	}
+
+	failedAt, err := ReadFetchFailure()
+	if err != nil {
+		cacheLog.Debug("read fetch failure failed", "err", err.Error())
+	}
+	if time.Since(failedAt) < statusBarFetchBackoff {
+		matrixLog.Debug("skip fetch after recent failure", "failedAt", failedAt)
+	} else if _, err := os.Stat(filepath.Join(getConfigDir(), "matrix.json")); err == nil {
+		matrixConfig, err := GetMatrixConfig()
+		if err == nil && len(matrixConfig.Pass) == 0 {
+			usrConf, _ := ReadUserConfig()
+			if matrixConfig.Pass, _, err = lookupPassword(matrixConfig, silentPasswordSources(usrConf.Credentials)); err != nil {
+				stdio.Debug("lookup password failed: %s", err.Error())
+			}
+		}
+		if err == nil && len(matrixConfig.Pass) > 0 {
+			entries, flexiTimeBalance, err := FetchMatrixEntries(matrixConfig)
+			if err == nil {
+				if err := WriteCache(entries, flexiTimeBalance); err != nil {
+					cacheLog.Debug("write cache failed", "err", err.Error())
+				}
+				if err := ClearFetchFailure(); err != nil {
+					cacheLog.Debug("clear fetch failure failed", "err", err.Error())
+				}
+				return entries, flexiTimeBalance, time.Now(), nil
+			}
+			matrixLog.Debug("fetch matrix entries failed", "err", err.Error())
+			if err := WriteFetchFailure(); err != nil {
+				cacheLog.Debug("write fetch failure failed", "err", err.Error())
+			}
+		}
+	}
+
+	entries, flexiTimeBalance, cacheTime, ok, err = ReadCache(statusBarStaleCacheAge)
+	if err != nil {
+		return nil, 0, time.Time{}, err
+	}
+	if !ok {
+		return nil, 0, time.Time{}, fmt.Errorf("no entries cached for today, run gohome show")
+	}
+	return entries, flexiTimeBalance, cacheTime, nil
+}
+
+func cmdStatus() error {
+	format, ok := statusBarFormatters[cli.Status.Format]
+	if !ok {
+		return fmt.Errorf("unknown status format %q", cli.Status.Format)
+	}
+
+	line, err := getStatusBarLine(time.Duration(cli.Status.CacheTimeSeconds) * time.Second)
+	if err != nil {
+		line = newStatusBarErrorLine(err)
+	}
+	stdio.Println("%s", format(line))
+	return nil
+}
+
+func getStatusBarLine(maxCacheAge time.Duration) (statusBarLine, error) {
+	usrConf, err := ReadUserConfig()
+	if err != nil {
+		return statusBarLine{}, fmt.Errorf("read user config: %s", err.Error())
+	}
+	targetTime, err := parseTargetTime(cli.Status.TargetTime, usrConf)
+	if err != nil {
+		return statusBarLine{}, err
+	}
+
+	entries, flexiTimeBalance, cacheTime, err := loadStatusBarEntries(maxCacheAge)
+	if err != nil {
+		return statusBarLine{}, err
+	}
+	status, err := ComputeStatus(entries, flexiTimeBalance, targetTime, nil)
+	if err != nil {
+		return statusBarLine{}, err
+	}
+	status.CacheTime = cacheTime
+	return newStatusBarLine(status), nil
+}
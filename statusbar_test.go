@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusBarLine(t *testing.T) {
+	day := time.Date(2026, time.October, 14, 0, 0, 0, 0, time.Local)
+	status := &Status{
+		State:             EntryTypeCome,
+		AccountedWorkTime: 6*time.Hour + 42*time.Minute,
+		TargetTime:        8 * time.Hour,
+		LeaveTimes:        []LeaveTime{{WorkTime: 8 * time.Hour, Time: day.Add(16*time.Hour + 31*time.Minute), IsTarget: true}},
+	}
+
+	line := newStatusBarLine(status)
+	assert.Equal(t, "6:42 ▸ 16:31", line.Text)
+	assert.Equal(t, StatusBarWorking, line.Class)
+	assert.Equal(t, 67, line.Percentage)
+	assert.Contains(t, line.Tooltip, "go home (08:00) at 16:31")
+
+	status.AccountedWorkTime = 8*time.Hour + 5*time.Minute
+	assert.Equal(t, StatusBarOvertime, newStatusBarLine(status).Class)
+	status.AccountedWorkTime = 9*time.Hour + 35*time.Minute
+	assert.Equal(t, StatusBarWarning, newStatusBarLine(status).Class)
+	status.AccountedWorkTime = 9*time.Hour + 50*time.Minute
+	assert.Equal(t, StatusBarCritical, newStatusBarLine(status).Class)
+	status.State = EntryTypeLeave
+	line = newStatusBarLine(status)
+	assert.Equal(t, StatusBarStopped, line.Class)
+	assert.Equal(t, "9:50 ■", line.Text)
+}
+
+func TestStatusBarFormats(t *testing.T) {
+	line := statusBarLine{Text: "6:42 ▸ 16:31", Tooltip: "a\nb", Class: StatusBarWarning, Percentage: 67}
+
+	var waybar map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(formatStatusBarWaybar(line)), &waybar))
+	assert.Equal(t, "6:42 ▸ 16:31", waybar["text"])
+	assert.Equal(t, "a\nb", waybar["tooltip"])
+	assert.Equal(t, "warning", waybar["class"])
+
+	assert.Equal(t, "6:42 ▸ 16:31\n6:42\n#ffb86c", formatStatusBarI3Blocks(line))
+	assert.Equal(t, "%{F#ffb86c}6:42 ▸ 16:31%{F-}", formatStatusBarPolybar(line))
+	assert.Equal(t, "#[fg=#ffb86c]6:42 ▸ 16:31#[default]", formatStatusBarTmux(line))
+	assert.Equal(t, "6:42 ▸ 16:31 | color=#ffb86c\n---\na\nb", formatStatusBarXbar(line))
+}
+
+func TestStatusBarFromCache(t *testing.T) {
+	useTempConfigDir(t)
+	_, err := getStatusBarLine(10 * time.Minute)
+	assert.Error(t, err)
+
+	require.NoError(t, WriteCache([]Entry{{Type: EntryTypeCome, Time: time.Now().Add(-time.Minute)}}, 0))
+	line, err := getStatusBarLine(10 * time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, StatusBarWorking, line.Class)
+	assert.Contains(t, line.Tooltip, "updated ")
+}
+
+func TestFetchFailure(t *testing.T) {
+	useTempConfigDir(t)
+	failedAt, err := ReadFetchFailure()
+	require.NoError(t, err)
+	assert.True(t, failedAt.IsZero())
+
+	require.NoError(t, WriteFetchFailure())
+	failedAt, err = ReadFetchFailure()
+	require.NoError(t, err)
+	assert.Less(t, time.Since(failedAt), statusBarFetchBackoff)
+
+	require.NoError(t, ClearFetchFailure())
+	failedAt, err = ReadFetchFailure()
+	require.NoError(t, err)
+	assert.True(t, failedAt.IsZero())
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+var (
+	// builtinThemes contain styles like "bold #268bd2" for all colors.
+	builtinThemes = map[string]colorsDef{
+		"default": {
+			ComeEntry: "dim green", LeaveEntry: "dim red", TripEntry: "dim yellow", CacheHint: "bold black", WorkTime: "bold white",
+			BreakEntry: "dim white", BreakInfo: "bold black", LeaveTime: "bold blue", FlexiTimePlus: "reset green", FlexiTimeMinus: "reset red",
+		},
+		"dark": {
+			ComeEntry: "#5fd787", LeaveEntry: "#ff5f5f", TripEntry: "#ffd75f", CacheHint: "italic #808080", WorkTime: "bold #ffffff",
+			BreakEntry: "#bcbcbc", BreakInfo: "#808080", LeaveTime: "bold #5fafff", FlexiTimePlus: "#87d787", FlexiTimeMinus: "#ff8787",
+		},
+		"light": {
+			ComeEntry: "#008700", LeaveEntry: "#af0000", TripEntry: "#af5f00", CacheHint: "italic #8a8a8a", WorkTime: "bold #000000",
+			BreakEntry: "#585858", BreakInfo: "#8a8a8a", LeaveTime: "bold #005faf", FlexiTimePlus: "#008700", FlexiTimeMinus: "#d70000",
+		},
+		"solarized": {
+			ComeEntry: "#859900", LeaveEntry: "#dc322f", TripEntry: "#b58900", CacheHint: "italic #586e75", WorkTime: "bold #93a1a1",
+			BreakEntry: "#839496", BreakInfo: "#586e75", LeaveTime: "bold #268bd2", FlexiTimePlus: "#2aa198", FlexiTimeMinus: "#cb4b16",
+		},
+	}
+
+	// namedColors maps color names to the index in the ANSI palette.
+	namedColors = map[string]int{
+		"black": 0, "red": 1, "green": 2, "yellow": 3, "blue": 4, "magenta": 5, "cyan": 6, "white": 7,
+		"gray": 8, "grey": 8, "bright-black": 8, "bright-red": 9, "bright-green": 10, "bright-yellow": 11,
+		"bright-blue": 12, "bright-magenta": 13, "bright-cyan": 14, "bright-white": 15,
+	}
+	styleCodes = map[string]string{
+		"reset": "0", "bold": "1", "dim": "2", "italic": "3", "underline": "4", "blink": "5", "reverse": "7", "strikethrough": "9",
+	}
+)
+
+// parseStyle converts a style like "bold underline #ff8700 on blue" to SGR parameters. Plain SGR parameters like "1;34" are returned unchanged.
+func parseStyle(style string) (string, error) {
+	style = strings.TrimSpace(style)
+	if patternColor.MatchString(style) {
+		return style, nil
+	}
+	words := strings.Fields(strings.ToLower(style))
+	if len(words) == 0 {
+		return "", fmt.Errorf("empty style")
+	}
+
+	params := make([]string, 0, len(words))
+	var hasForeground, hasBackground bool
+	for i := 0; i < len(words); i++ {
+		if code, ok := styleCodes[words[i]]; ok {
+			params = append(params, code)
+			continue
+		}
+		if words[i] == "0" {
+			// plain SGR reset as in dumped colors like "0;32"
+			params = append(params, "0")
+			continue
+		}
+
+		background := words[i] == "on"
+		if background {
+			if i+1 >= len(words) {
+				return "", fmt.Errorf("word %d: missing background color after \"on\"", i+1)
+			}
+			i++
+			if hasBackground {
+				return "", fmt.Errorf("word %d: background color given twice", i+1)
+			}
+			hasBackground = true
+		} else {
+			if hasForeground {
+				return "", fmt.Errorf("word %d: foreground color given twice, use \"on %s\" for a background color", i+1, words[i])
+			}
+			hasForeground = true
+		}
+		p, err := colorParams(words[i], background)
+		if err != nil {
+			return "", fmt.Errorf("word %d: %s", i+1, err.Error())
+		}
+		params = append(params, p)
+	}
+	return strings.Join(params, ";"), nil
+}
+
+// colorParams returns the SGR parameters of a named or hex color.
+func colorParams(name string, background bool) (string, error) {
+	base, extended := 30, "38"
+	if background {
+		base, extended = 40, "48"
+	}
+
+	if strings.HasPrefix(name, "#") {
+		hex := name[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		rgb, err := strconv.ParseUint(hex, 16, 32)
+		if len(hex) != 6 || err != nil {
+			return "", fmt.Errorf("invalid hex color %q, expected #rrggbb or #rgb", name)
+		}
+		return fmt.Sprintf("%s;2;%d;%d;%d", extended, rgb>>16, (rgb>>8)&0xff, rgb&0xff), nil
+	}
+
+	index, ok := namedColors[name]
+	if !ok {
+		return "", fmt.Errorf("unknown color or style %q, expected a color like \"blue\", \"bright-blue\" or \"#268bd2\" or one of %s", name, strings.Join(sortedKeys(styleCodes), ", "))
+	}
+	if index >= 8 {
+		return strconv.Itoa(base + 60 + index - 8), nil
+	}
+	return strconv.Itoa(base + index), nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// applyTheme sets all colors of dst that are given in theme. Nothing is changed if any style is invalid, the error lists all invalid styles.
+func applyTheme(dst *colorsDef, theme colorsDef) error {
+	result := *dst
+	resultFields := colorFields(&result)
+	errs := make([]error, 0)
+	for i, f := range colorFields(&theme) {
+		if len(*f.Value) == 0 {
+			continue
+		}
+		params, err := parseStyle(*f.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", f.Name, err.Error()))
+			continue
+		}
+		*resultFields[i].Value = fmt.Sprintf("\033[%sm", params)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	*dst = result
+	return nil
+}
+
+// readTheme reads a theme from a JSON file with color names as keys. Unknown keys are rejected.
+func readTheme(file string) (colorsDef, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return colorsDef{}, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var theme colorsDef
+	if err := decoder.Decode(&theme); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return colorsDef{}, fmt.Errorf("line %d: %s", 1+bytes.Count(data[:syntaxErr.Offset], []byte("\n")), err.Error())
+		}
+		return colorsDef{}, fmt.Errorf("%s, valid keys are %s", err.Error(), strings.Join(colorNames(), ", "))
+	}
+	return theme, nil
+}
+
+func colorNames() []string {
+	names := make([]string, 0)
+	for _, f := range colorFields(&colorsDef{}) {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// loadTheme returns the built-in theme of the given name or reads a theme file.
+func loadTheme(name string) (colorsDef, error) {
+	if theme, ok := builtinThemes[name]; ok {
+		return theme, nil
+	}
+	if _, err := os.Stat(name); err != nil {
+		return colorsDef{}, fmt.Errorf("unknown theme %q, expected one of %s or a theme file", name, strings.Join(sortedKeys(builtinThemes), ", "))
+	}
+	theme, err := readTheme(name)
+	if err != nil {
+		return colorsDef{}, fmt.Errorf("read theme %s: %s", name, err.Error())
+	}
+	return theme, nil
+}
+
+// themeColors returns the escape sequences of a theme for the color support of the terminal. Missing colors are taken from the default theme.
+func themeColors(theme colorsDef, level stdio.ColorLevel) (colorsDef, error) {
+	var c colorsDef
+	if err := applyTheme(&c, builtinThemes["default"]); err != nil {
+		return colorsDef{}, err
+	}
+	if err := applyTheme(&c, theme); err != nil {
+		return colorsDef{}, err
+	}
+	if level == stdio.ColorNone {
+		return colorsDef{}, nil
+	}
+	adaptColors(&c, level)
+	return c, nil
+}
+
+// renderThemePreview returns sample output using the given colors.
+func renderThemePreview(c colorsDef, end string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " %s--> 08:00%s  %s<-- 12:00%s  %s<-- 14:00 DG%s  %s(cache from 16:02:11)%s\n", c.ComeEntry, end, c.LeaveEntry, end, c.TripEntry, end, c.CacheHint, end)
+	fmt.Fprintf(&sb, " worktime: %s07:42:00%s (%s-00:18%s)  %sbreak: 00:30%s\n", c.WorkTime, end, c.FlexiTimeMinus, end, c.BreakEntry, end)
+	fmt.Fprintf(&sb, " flexi-time balance: %s+01:30%s  go home at %s16:30%s %s(00:30 break)%s\n", c.FlexiTimePlus, end, c.LeaveTime, end, c.BreakInfo, end)
+	return sb.String()
+}
+
+func cmdThemeList() error {
+	for _, name := range sortedKeys(builtinThemes) {
+		stdio.Println("%s", name)
+	}
+	return nil
+}
+
+func cmdThemePreview() error {
+	names := cli.Theme.Preview.Themes
+	if len(names) == 0 {
+		names = sortedKeys(builtinThemes)
+	}
+	level := stdio.ColorSupport()
+
+	for i, name := range names {
+		theme, err := loadTheme(name)
+		if err != nil {
+			return err
+		}
+		c, err := themeColors(theme, level)
+		if err != nil {
+			return fmt.Errorf("theme %s is invalid:\n%s", name, err.Error())
+		}
+		if i > 0 {
+			stdio.Println("")
+		}
+		stdio.Println("%s", name)
+		stdio.Print("%s", renderThemePreview(c, colorEnd))
+	}
+	return nil
+}
+
+func cmdThemeApply() error {
+	theme, err := loadTheme(cli.Theme.Apply.Theme)
+	if err != nil {
+		return err
+	}
+	if _, err := themeColors(theme, stdio.ColorTrue); err != nil {
+		return fmt.Errorf("theme %s is invalid:\n%s", cli.Theme.Apply.Theme, err.Error())
+	}
+
+	data, err := json.MarshalIndent(theme, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal theme to json")
+	}
+	dir := getConfigDir()
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create config dir: %s", err.Error())
+	}
+	outputFilePath := filepath.Join(dir, "colors.json")
+	if err := os.WriteFile(outputFilePath, data, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write colors: %s", err.Error())
+	}
+	stdio.Info("applied theme %s to %s", cli.Theme.Apply.Theme, outputFilePath)
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStyle(t *testing.T) {
+	for style, params := range map[string]string{
+		"1;34":                       "1;34",
+		"blue":                       "34",
+		"bold Bright-Blue":           "1;94",
+		"italic underline #ff8700":   "3;4;38;2;255;135;0",
+		"#f80 on #303030":            "38;2;255;136;0;48;2;48;48;48",
+		"reverse on grey":            "7;100",
+		"  dim   green  ":            "2;32",
+		"strikethrough white on red": "9;37;41",
+		"reset green":                "0;32",
+		"0 bold red":                 "0;1;31",
+	} {
+		p, err := parseStyle(style)
+		require.NoError(t, err, style)
+		assert.Equal(t, params, p, style)
+	}
+
+	for style, msg := range map[string]string{
+		"":               "empty style",
+		"bold bleu":      `word 2: unknown color or style "bleu"`,
+		"#ff87":          `word 1: invalid hex color "#ff87", expected #rrggbb or #rgb`,
+		"#gggggg":        `word 1: invalid hex color "#gggggg"`,
+		"red on":         `word 2: missing background color after "on"`,
+		"red blue":       `word 2: foreground color given twice, use "on blue" for a background color`,
+		"on red on blue": `word 4: background color given twice`,
+	} {
+		_, err := parseStyle(style)
+		require.Error(t, err, style)
+		assert.Contains(t, err.Error(), msg, style)
+	}
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	for name, theme := range builtinThemes {
+		c, err := themeColors(theme, stdio.ColorBasic)
+		require.NoError(t, err, name)
+		for _, f := range colorFields(&c) {
+			assert.NotEmpty(t, *f.Value, "%s.%s", name, f.Name)
+		}
+	}
+
+	// the default theme reproduces the classic colors
+	c, err := themeColors(builtinThemes["default"], stdio.ColorTrue)
+	require.NoError(t, err)
+	assert.Equal(t, colorsDef{
+		ComeEntry: "\033[2;32m", LeaveEntry: "\033[2;31m", TripEntry: "\033[2;33m", CacheHint: "\033[1;30m", WorkTime: "\033[1;37m",
+		BreakEntry: "\033[2;37m", BreakInfo: "\033[1;30m", LeaveTime: "\033[1;34m", FlexiTimePlus: "\033[0;32m", FlexiTimeMinus: "\033[0;31m",
+	}, c)
+}
+
+func TestApplyTheme(t *testing.T) {
+	c := colorsDef{WorkTime: "\033[1;37m"}
+	err := applyTheme(&c, colorsDef{LeaveTime: "bold #268bd2", WorkTime: "bold bleu", BreakInfo: "on"})
+	require.Error(t, err)
+	assert.Equal(t, `WorkTime: word 2: unknown color or style "bleu", expected a color like "blue", "bright-blue" or "#268bd2" or one of blink, bold, dim, italic, reset, reverse, strikethrough, underline
+BreakInfo: word 1: missing background color after "on"`, err.Error())
+	// nothing is applied for invalid themes
+	assert.Equal(t, colorsDef{WorkTime: "\033[1;37m"}, c)
+
+	require.NoError(t, applyTheme(&c, colorsDef{LeaveTime: "bold #268bd2"}))
+	assert.Equal(t, "\033[1;38;2;38;139;210m", c.LeaveTime)
+	assert.Equal(t, "\033[1;37m", c.WorkTime)
+}
+
+func TestReadTheme(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "theme.json")
+
+	require.NoError(t, os.WriteFile(file, []byte("{\n  \"WorkTime\": \"bold\"\n}"), 0600))
+	theme, err := readTheme(file)
+	require.NoError(t, err)
+	assert.Equal(t, colorsDef{WorkTime: "bold"}, theme)
+
+	require.NoError(t, os.WriteFile(file, []byte("{\n  \"WorkTime\": \"bold\",\n  \"WorkTim\": \"red\"\n}"), 0600))
+	_, err = readTheme(file)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "WorkTim", valid keys are ComeEntry, LeaveEntry`)
+
+	require.NoError(t, os.WriteFile(file, []byte("{\n  \"WorkTime\": \"bold\"\n  \"LeaveTime\": \"red\"\n}"), 0600))
+	_, err = readTheme(file)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3: ")
+}
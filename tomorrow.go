@@ -0,0 +1,61 @@
+package main
+
+import (
+	"time"
+)
+
+const (
+	// businessHoursStart is the usual earliest start of a day. Plans are only relevant if the rest period ends later.
+	businessHoursStart = 6*time.Hour + 30*time.Minute
+)
+
+// TomorrowPlan is the earliest start and leave time of the next day with respect to the rest period.
+type TomorrowPlan struct {
+	// Leave is today's leave time the plan is based on.
+	Leave time.Time
+	// Projected is true if Leave is the target leave time because the clock is still ticking.
+	Projected bool
+	// Start is the earliest allowed start tomorrow.
+	Start time.Time
+	// TargetTime is tomorrow's target time and LeaveTime the earliest time to reach it.
+	TargetTime time.Duration
+	LeaveTime  time.Time
+	BreakTime  time.Duration
+	// Restricted is true if the rest period delays the start beyond the usual business hours.
+	Restricted bool
+}
+
+// PlanTomorrow returns the earliest start and leave time of the next day based on today's leave booking, or the target leave time while still working. False is returned if the rest period rule is disabled.
+func PlanTomorrow(conf ComplianceConfig, status *Status, tomorrowTarget time.Duration) (*TomorrowPlan, bool) {
+	plan := &TomorrowPlan{TargetTime: tomorrowTarget}
+	if status.Ticking() {
+		plan.Leave = status.TargetLeaveTime().Time
+		if now := time.Now(); plan.Leave.Before(now) {
+			plan.Leave = now
+		}
+		plan.Projected = true
+	} else {
+		plan.Leave = status.Entries[len(status.Entries)-1].Time
+	}
+
+	var ok bool
+	plan.Start, ok = EarliestStart(conf, plan.Leave)
+	if !ok {
+		return nil, false
+	}
+	// the rest period of short days ends before midnight
+	tomorrow := truncateDay(plan.Leave).AddDate(0, 0, 1)
+	if plan.Start.Before(tomorrow) {
+		plan.Start = tomorrow
+	}
+	plan.Restricted = plan.Start.After(tomorrow.Add(businessHoursStart))
+
+	leaveTime, err := GetLeaveTime(plan.Start, 0, tomorrowTarget)
+	if err != nil {
+		// no leave time for targets above the maximum work time
+		return plan, true
+	}
+	plan.LeaveTime = leaveTime
+	plan.BreakTime = leaveTime.Sub(plan.Start) - tomorrowTarget
+	return plan, true
+}
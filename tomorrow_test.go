@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanTomorrow(t *testing.T) {
+	day := time.Date(2026, time.October, 14, 0, 0, 0, 0, time.Local)
+	status, err := ComputeStatus([]Entry{
+		{Type: EntryTypeCome, Time: day.Add(dur(10, 0))},
+		{Type: EntryTypeLeave, Time: day.Add(dur(20, 12))},
+	}, 0, 8*time.Hour, nil)
+	require.NoError(t, err)
+
+	plan, ok := PlanTomorrow(ComplianceConfig{}, status, 7*time.Hour)
+	require.True(t, ok)
+	assert.False(t, plan.Projected)
+	assert.True(t, plan.Restricted)
+	assert.Equal(t, day.AddDate(0, 0, 1).Add(dur(7, 12)), plan.Start)
+	assert.Equal(t, day.AddDate(0, 0, 1).Add(dur(14, 42)), plan.LeaveTime)
+	assert.Equal(t, dur(0, 30), plan.BreakTime)
+
+	plan, ok = PlanTomorrow(ComplianceConfig{Rules: map[string]ComplianceRuleConfig{"rest-period": {Limit: "09:00"}}}, status, 7*time.Hour)
+	require.True(t, ok)
+	assert.False(t, plan.Restricted)
+
+	_, ok = PlanTomorrow(ComplianceConfig{Rules: map[string]ComplianceRuleConfig{"rest-period": {Severity: ComplianceSeverityOff}}}, status, 7*time.Hour)
+	assert.False(t, ok)
+}
+
+func TestPlanTomorrowShortDay(t *testing.T) {
+	day := time.Date(2026, time.October, 14, 0, 0, 0, 0, time.Local)
+	status, err := ComputeStatus([]Entry{
+		{Type: EntryTypeCome, Time: day.Add(dur(8, 0))},
+		{Type: EntryTypeLeave, Time: day.Add(dur(12, 0))},
+	}, 0, 4*time.Hour, nil)
+	require.NoError(t, err)
+
+	plan, ok := PlanTomorrow(ComplianceConfig{}, status, 4*time.Hour)
+	require.True(t, ok)
+	assert.False(t, plan.Restricted)
+	assert.Equal(t, day.AddDate(0, 0, 1), plan.Start)
+	assert.Equal(t, day.AddDate(0, 0, 1).Add(dur(4, 0)), plan.LeaveTime)
+}
+
+func TestShowTemplateTomorrow(t *testing.T) {
+	oldColors, oldColorEnd := colors, colorEnd
+	disableColors()
+	defer func() { colors, colorEnd = oldColors, oldColorEnd }()
+
+	data := testShowData(t)
+	tomorrow := truncateDay(data.Now).AddDate(0, 0, 1)
+	data.Tomorrow = &TomorrowPlan{Leave: data.Now, Projected: true, Start: tomorrow.Add(dur(8, 0)), TargetTime: 8 * time.Hour, LeaveTime: tomorrow.Add(dur(16, 30)), BreakTime: dur(0, 30)}
+
+	tmpl, err := loadShowTemplate("default")
+	require.NoError(t, err)
+	var sb strings.Builder
+	require.NoError(t, renderShowTemplate(&sb, tmpl, data))
+	assert.True(t, strings.HasSuffix(sb.String(), `go home (08:00) at 16:30 (00:30 break)
+-----------------------------------------------------
+earliest start tomorrow: 08:00 (leaving at 17:00)
+go home tomorrow (08:00) at 16:30 (00:30 break)
+`), sb.String())
+}
@@ -7,12 +7,16 @@ import (
 )
 
 type UserConfig struct {
-	TargetTimeStr string `json:"TargetTime"`
+	TargetTimeStr string           `json:"TargetTime"`
+	RedactTerms   []string         `json:"RedactTerms,omitempty"`
+	Reminders     ReminderConfig   `json:"Reminders,omitzero"`
+	ShowTemplate  string           `json:"ShowTemplate,omitempty"`
+	Compliance    ComplianceConfig `json:"Compliance,omitzero"`
+	Credentials   CredentialConfig `json:"Credentials,omitzero"`
 }
 
 func ReadUserConfig() (UserConfig, error) {
 	configDir := getConfigDir()
-	matrixOutputFileDir = configDir
 	userConfFile := filepath.Join(configDir, "userconfig.json")
 
 	data, err := os.ReadFile(userConfFile)
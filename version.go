@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/sbreitf1/gohome/internal/pkg/stdio"
+)
+
+var (
+	// version and buildDate are set by the package scripts via -ldflags "-X main.version=1.2.3". Otherwise the module version and commit time from the build info are used.
+	version   string
+	buildDate string
+)
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	Modified  bool
+	BuildDate string
+	GoVersion string
+	Platform  string
+}
+
+// readBuildInfo returns version information embedded by the linker and the Go toolchain.
+func readBuildInfo() BuildInfo {
+	info := BuildInfo{GoVersion: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info = parseBuildInfo(bi)
+	}
+	if len(version) > 0 {
+		info.Version = version
+	}
+	if len(buildDate) > 0 {
+		info.BuildDate = buildDate
+	}
+	return info
+}
+
+func parseBuildInfo(bi *debug.BuildInfo) BuildInfo {
+	info := BuildInfo{Version: strings.TrimPrefix(bi.Main.Version, "v"), GoVersion: bi.GoVersion}
+	if info.Version == "(devel)" {
+		info.Version = ""
+	}
+	var goos, goarch string
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Commit = s.Value
+		case "vcs.time":
+			info.BuildDate = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		case "GOOS":
+			goos = s.Value
+		case "GOARCH":
+			goarch = s.Value
+		}
+	}
+	if len(goos) > 0 && len(goarch) > 0 {
+		info.Platform = goos + "/" + goarch
+	} else {
+		info.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	}
+	return info
+}
+
+// Short returns the version in a single line like "gohome 1.2.3 (abc1234)".
+func (info BuildInfo) Short() string {
+	str := "gohome " + valueOrUnknown(info.Version)
+	if len(info.Commit) > 0 {
+		commit := info.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if info.Modified {
+			commit += ", modified"
+		}
+		str += " (" + commit + ")"
+	}
+	return str
+}
+
+// String returns all build information, one per line.
+func (info BuildInfo) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "version:    %s\n", valueOrUnknown(info.Version))
+	commit := valueOrUnknown(info.Commit)
+	if info.Modified {
+		commit += " (modified)"
+	}
+	fmt.Fprintf(&sb, "commit:     %s\n", commit)
+	fmt.Fprintf(&sb, "build date: %s\n", valueOrUnknown(info.BuildDate))
+	fmt.Fprintf(&sb, "go version: %s %s\n", info.GoVersion, info.Platform)
+	return sb.String()
+}
+
+func cmdVersion() error {
+	stdio.Print("%s", readBuildInfo().String())
+	if !cli.Version.Remote {
+		return nil
+	}
+
+	matrixConfig, err := getMatrixConfigWithPassword()
+	if err != nil {
+		return err
+	}
+	client := newMatrixClient(matrixConfig)
+	if err := client.login(); err != nil {
+		return fmt.Errorf("login: %s", err.Error())
+	}
+	defer client.Close()
+	stdio.Println("matrix:     %s (%s)", valueOrUnknown(client.ServerVersion()), matrixConfig.Host)
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBuildInfo(t *testing.T) {
+	info := parseBuildInfo(&debug.BuildInfo{
+		GoVersion: "go1.25.1",
+		Main:      debug.Module{Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "GOOS", Value: "linux"},
+			{Key: "GOARCH", Value: "arm64"},
+			{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+			{Key: "vcs.time", Value: "2026-10-14T08:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+	assert.Equal(t, BuildInfo{Version: "1.2.3", Commit: "0123456789abcdef0123", Modified: true, BuildDate: "2026-10-14T08:00:00Z", GoVersion: "go1.25.1", Platform: "linux/arm64"}, info)
+	assert.Equal(t, "gohome 1.2.3 (0123456789ab, modified)", info.Short())
+	assert.Equal(t, `version:    1.2.3
+commit:     0123456789abcdef0123 (modified)
+build date: 2026-10-14T08:00:00Z
+go version: go1.25.1 linux/arm64
+`, info.String())
+
+	info = parseBuildInfo(&debug.BuildInfo{GoVersion: "go1.25.1", Main: debug.Module{Version: "(devel)"}})
+	assert.Equal(t, "gohome unknown", info.Short())
+}
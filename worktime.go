@@ -155,3 +155,127 @@ func GetLeaveTime(startTime time.Time, breakTime, targetWorkTime time.Duration)
 		}
 	}
 }
+
+// EntryGap describes a booking that is probably missing in a list of entries.
+type EntryGap struct {
+	// Type is the type of the missing entry.
+	Type EntryType
+	// After is the time of the entry after which the missing entry is expected. It is zero for gaps before the first entry.
+	After time.Time
+	// Before is the time of the entry before which the missing entry is expected. It is zero for gaps after the last entry.
+	Before time.Time
+}
+
+// String returns a human readable description of the gap.
+func (gap EntryGap) String() string {
+	if gap.After.IsZero() {
+		return fmt.Sprintf("missing %s booking before %s", gap.Type, gap.Before.Format("15:04"))
+	}
+	if gap.Before.IsZero() {
+		return fmt.Sprintf("missing %s booking after %s", gap.Type, gap.After.Format("15:04"))
+	}
+	return fmt.Sprintf("missing %s booking between %s and %s", gap.Type, gap.After.Format("15:04"), gap.Before.Format("15:04"))
+}
+
+// FindEntryGaps returns all missing bookings that prevent a valid sequence of come, trip and leave entries.
+func FindEntryGaps(entries []Entry) []EntryGap {
+	gaps := make([]EntryGap, 0)
+	state := EntryTypeLeave
+	var last time.Time
+	for _, entry := range entries {
+		switch entry.Type {
+		case EntryTypeCome:
+			if state == EntryTypeCome {
+				gaps = append(gaps, EntryGap{Type: EntryTypeLeave, After: last, Before: entry.Time})
+			}
+		case EntryTypeLeave, EntryTypeTrip:
+			if state != EntryTypeCome {
+				gaps = append(gaps, EntryGap{Type: EntryTypeCome, After: last, Before: entry.Time})
+			}
+		}
+		state = entry.Type
+		last = entry.Time
+	}
+	if state == EntryTypeTrip && len(entries) > 0 && !isToday(last) {
+		gaps = append(gaps, EntryGap{Type: EntryTypeCome, After: last})
+	}
+	if state == EntryTypeCome && len(entries) > 0 && !isToday(last) {
+		gaps = append(gaps, EntryGap{Type: EntryTypeLeave, After: last})
+	}
+	return gaps
+}
+
+func isToday(t time.Time) bool {
+	now := time.Now()
+	return t.Year() == now.Year() && t.Month() == now.Month() && t.Day() == now.Day()
+}
+
+// WorkBlock is a continuous presence from a come to a leave entry. Business trips do not interrupt a block.
+type WorkBlock struct {
+	Start time.Time
+	End   time.Time
+	Trips []time.Time
+}
+
+// DaySummary contains the computed times of a single day.
+type DaySummary struct {
+	Date               time.Time
+	Entries            []Entry
+	Blocks             []WorkBlock
+	WorkTime           time.Duration
+	BreakTime          time.Duration
+	AccountedWorkTime  time.Duration
+	AccountedBreakTime time.Duration
+	FlexiTime          time.Duration
+	// Incomplete is set for past days without final leave entry. No times are computed then.
+	Incomplete bool
+}
+
+// SummarizeDay computes work and break times of a day. The last block of today ends at the current time.
+func SummarizeDay(date time.Time, entries []Entry, targetTime time.Duration) (DaySummary, error) {
+	summary := DaySummary{Date: truncateDay(date), Entries: entries}
+	if len(entries) == 0 {
+		return summary, ErrNoEntries
+	}
+
+	var block *WorkBlock
+	for _, e := range entries {
+		switch e.Type {
+		case EntryTypeCome:
+			if block == nil {
+				block = &WorkBlock{Start: e.Time}
+			}
+		case EntryTypeTrip:
+			if block != nil {
+				block.Trips = append(block.Trips, e.Time)
+			}
+		case EntryTypeLeave:
+			if block != nil {
+				block.End = e.Time
+				summary.Blocks = append(summary.Blocks, *block)
+				block = nil
+			}
+		}
+	}
+	if block != nil {
+		if !isToday(date) {
+			summary.Blocks = append(summary.Blocks, *block)
+			summary.Incomplete = true
+			return summary, nil
+		}
+		block.End = time.Now()
+		summary.Blocks = append(summary.Blocks, *block)
+	}
+
+	var err error
+	summary.WorkTime, _, summary.BreakTime, err = ComputeWorkTime(entries)
+	if err != nil {
+		return summary, err
+	}
+	summary.AccountedWorkTime, summary.AccountedBreakTime, err = ComputeAccountedWorkTime(summary.WorkTime, summary.BreakTime)
+	if err != nil {
+		return summary, err
+	}
+	summary.FlexiTime = noSeconds(summary.AccountedWorkTime) - targetTime
+	return summary, nil
+}
@@ -97,3 +97,29 @@ func dur(hours, minutes int) time.Duration {
 func tim(hours, minutes int) time.Time {
 	return time.Date(2019, time.November, 1, hours, minutes, 0, 0, time.UTC)
 }
+
+func TestFindEntryGaps(t *testing.T) {
+	assert.Empty(t, FindEntryGaps(nil))
+	assert.Empty(t, FindEntryGaps([]Entry{
+		{Type: EntryTypeCome, Time: tim(8, 0)},
+		{Type: EntryTypeTrip, Time: tim(10, 0)},
+		{Type: EntryTypeCome, Time: tim(11, 0)},
+		{Type: EntryTypeLeave, Time: tim(16, 0)},
+	}))
+
+	assert.Equal(t, []EntryGap{
+		{Type: EntryTypeCome, Before: tim(12, 0)},
+		{Type: EntryTypeLeave, After: tim(12, 30), Before: tim(13, 0)},
+		{Type: EntryTypeCome, After: tim(14, 0), Before: tim(17, 0)},
+	}, FindEntryGaps([]Entry{
+		{Type: EntryTypeLeave, Time: tim(12, 0)},
+		{Type: EntryTypeCome, Time: tim(12, 30)},
+		{Type: EntryTypeCome, Time: tim(13, 0)},
+		{Type: EntryTypeTrip, Time: tim(14, 0)},
+		{Type: EntryTypeLeave, Time: tim(17, 0)},
+	}))
+
+	// open entries of past days are missing their leave booking
+	assert.Equal(t, []EntryGap{{Type: EntryTypeLeave, After: tim(8, 0)}}, FindEntryGaps([]Entry{{Type: EntryTypeCome, Time: tim(8, 0)}}))
+	assert.Equal(t, "missing leave booking after 08:00", EntryGap{Type: EntryTypeLeave, After: tim(8, 0)}.String())
+}
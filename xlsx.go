@@ -0,0 +1,155 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Timesheet" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+	// xlsxStyles defines the cell formats for dates (1), durations (2) and times of day (3).
+	xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="[h]:mm"/></numFmts>
+<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="20" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+</cellXfs>
+</styleSheet>`
+
+	xlsxStyleDate     = 1
+	xlsxStyleDuration = 2
+	xlsxStyleTime     = 3
+)
+
+var (
+	// xlsxEpoch is the base of spreadsheet date values.
+	xlsxEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// xlsxCell is a string cell or a numeric cell with a style.
+type xlsxCell struct {
+	Str   string
+	Num   float64
+	Style int
+	IsNum bool
+}
+
+// writeExportXLSX writes a spreadsheet with one row per day. Times and durations are stored as numbers to allow computations.
+func writeExportXLSX(w io.Writer, days []DaySummary) error {
+	rows := [][]xlsxCell{make([]xlsxCell, 0, len(exportHeader))}
+	for _, h := range exportHeader {
+		rows[0] = append(rows[0], xlsxCell{Str: h})
+	}
+
+	for _, s := range days {
+		record := exportRecord(s)
+		date := time.Date(s.Date.Year(), s.Date.Month(), s.Date.Day(), 0, 0, 0, 0, time.UTC)
+		row := []xlsxCell{{Num: date.Sub(xlsxEpoch).Hours() / 24, Style: xlsxStyleDate, IsNum: true}}
+		for _, t := range []func() (time.Time, bool){s.Come, s.Leave} {
+			if v, ok := t(); ok {
+				row = append(row, xlsxCell{Num: xlsxTimeOfDay(v), Style: xlsxStyleTime, IsNum: true})
+			} else {
+				row = append(row, xlsxCell{})
+			}
+		}
+		row = append(row, xlsxCell{Str: record[3]})
+		if s.Incomplete {
+			row = append(row, xlsxCell{}, xlsxCell{}, xlsxCell{}, xlsxCell{}, xlsxCell{})
+		} else {
+			for _, d := range []time.Duration{s.WorkTime, s.BreakTime, s.AccountedWorkTime, s.AccountedBreakTime} {
+				row = append(row, xlsxCell{Num: d.Hours() / 24, Style: xlsxStyleDuration, IsNum: true})
+			}
+			// negative durations cannot be formatted as time
+			row = append(row, xlsxCell{Str: record[8]})
+		}
+		row = append(row, xlsxCell{Str: record[9]})
+		rows = append(rows, row)
+	}
+
+	zw := zip.NewWriter(w)
+	files := []struct{ Name, Content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(rows)},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.Content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func xlsxTimeOfDay(t time.Time) float64 {
+	return float64(t.Hour()*3600+t.Minute()*60+t.Second()) / 86400
+}
+
+func xlsxSheet(rows [][]xlsxCell) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range rows {
+		fmt.Fprintf(&sb, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := fmt.Sprintf("%s%d", xlsxColumn(c), r+1)
+			if cell.IsNum {
+				fmt.Fprintf(&sb, `<c r="%s" s="%d"><v>%g</v></c>`, ref, cell.Style, cell.Num)
+			} else if len(cell.Str) > 0 {
+				fmt.Fprintf(&sb, `<c r="%s" t="inlineStr"><is><t>`, ref)
+				xml.EscapeText(&sb, []byte(cell.Str))
+				sb.WriteString(`</t></is></c>`)
+			}
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	return sb.String()
+}
+
+// xlsxColumn returns the column name like "A" or "AB" for a zero-based index.
+func xlsxColumn(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}